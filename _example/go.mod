@@ -1,6 +1,6 @@
 module example
 
-go 1.18
+go 1.24.0
 
 require (
 	github.com/joho/godotenv v1.4.0
@@ -8,4 +8,10 @@ require (
 	github.com/libdns/libdns v1.1.0
 )
 
+require (
+	golang.org/x/net v0.50.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
+	golang.org/x/time v0.14.0 // indirect
+)
+
 replace github.com/libdns/godaddy => ../
@@ -33,13 +33,13 @@ func main() {
 	// Configure the provider with environment and timeout options
 	provider := godaddy.Provider{
 		APIToken:    token,
-		UseOTE:      false, // Set to true for testing environment
+		Environment: godaddy.EnvironmentProduction,
 		HTTPTimeout: 30 * time.Second,
 	}
 
 	// Check if we should use OTE environment from env var
 	if os.Getenv("GODADDY_USE_OTE") == "true" {
-		provider.UseOTE = true
+		provider.Environment = godaddy.EnvironmentOTE
 		fmt.Println("Using GoDaddy OTE (testing) environment")
 	} else {
 		fmt.Println("Using GoDaddy production environment")
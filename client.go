@@ -0,0 +1,1022 @@
+package godaddy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultMaxRetries is the number of retries used when Provider.MaxRetries is zero
+	defaultMaxRetries = 3
+
+	// defaultRetryBaseDelay is the initial delay of the exponential backoff
+	// used when Provider.RetryBaseDelay is zero
+	defaultRetryBaseDelay = 1 * time.Second
+
+	// defaultRetryMaxDelay caps the delay between two attempts when
+	// Provider.RetryMaxDelay is zero
+	defaultRetryMaxDelay = 30 * time.Second
+
+	// defaultPageSize is the number of records per page used when Provider.PageSize is zero
+	defaultPageSize = 500
+
+	// defaultMaxTTL is GoDaddy's maximum TTL, used when Provider.MaxTTL is zero
+	defaultMaxTTL = 604800 * time.Second
+
+	// defaultMinTTL is GoDaddy's minimum TTL, used when Provider.MinTTL is zero
+	defaultMinTTL = 600 * time.Second
+
+	// defaultRateLimitInterval is the interval used when Provider.RateLimitInterval is zero
+	defaultRateLimitInterval = time.Minute
+
+	// zoneInfoCacheTTL is how long the results of GetZoneInfo are cached
+	zoneInfoCacheTTL = time.Hour
+)
+
+// DefaultUserAgent is the User-Agent sent when Provider.UserAgent is empty
+const DefaultUserAgent = "libdns-godaddy/1.0"
+
+// Environments accepted by Provider.Environment
+const (
+	EnvironmentProduction = "production"
+	EnvironmentOTE        = "ote"
+)
+
+// environmentHosts maps each environment to its API base URL
+var environmentHosts = map[string]string{
+	EnvironmentProduction: "https://api.godaddy.com",
+	EnvironmentOTE:        "https://api.ote-godaddy.com",
+}
+
+func (p *Provider) getApiHost() string {
+	if p.APIEndpoint != "" {
+		return strings.TrimSuffix(p.APIEndpoint, "/")
+	}
+	if host, ok := environmentHosts[strings.ToLower(p.Environment)]; ok {
+		return host
+	}
+	if p.UseOTE {
+		return environmentHosts[EnvironmentOTE]
+	}
+	return environmentHosts[EnvironmentProduction]
+}
+
+// validateConfig returns an error if Environment is set to an unknown value or
+// Proxy is not a valid URL
+func (p *Provider) validateConfig() error {
+	if p.Environment != "" && p.APIEndpoint == "" {
+		if _, ok := environmentHosts[strings.ToLower(p.Environment)]; !ok {
+			return fmt.Errorf("unknown GoDaddy environment %q, expected %q or %q",
+				p.Environment, EnvironmentProduction, EnvironmentOTE)
+		}
+	}
+	if p.Proxy != "" && p.HTTPClient == nil {
+		if _, err := parseProxyURL(p.Proxy); err != nil {
+			return err
+		}
+	}
+	if p.getMinTTL() > p.getMaxTTL() {
+		return fmt.Errorf("MinTTL (%s) exceeds MaxTTL (%s)", p.getMinTTL(), p.getMaxTTL())
+	}
+	return nil
+}
+
+// parseProxyURL parses the Proxy field, which must be an absolute URL such as
+// http://proxy:3128 or socks5://proxy:1080
+func parseProxyURL(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxy)
+	}
+	return u, nil
+}
+
+// transportKey identifies the transports shared by providers with the same
+// proxy and TLS verification settings
+type transportKey struct {
+	proxy    string
+	insecure bool
+}
+
+var (
+	// sharedTransports holds one transport per proxy URL and TLS verification
+	// setting, so that providers with the same settings share a connection pool
+	sharedTransports   = make(map[transportKey]*http.Transport)
+	sharedTransportsMu sync.Mutex
+)
+
+// sharedTransport returns the shared transport sending requests through the
+// given proxy, if not nil, and skipping TLS verification if insecure is set
+func sharedTransport(proxy *url.URL, insecure bool) *http.Transport {
+	sharedTransportsMu.Lock()
+	defer sharedTransportsMu.Unlock()
+
+	key := transportKey{insecure: insecure}
+	if proxy != nil {
+		key.proxy = proxy.String()
+	}
+	if transport, ok := sharedTransports[key]; ok {
+		return transport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	sharedTransports[key] = transport
+	return transport
+}
+
+// skipTLSVerify reports whether InsecureSkipVerify applies, which requires
+// APIEndpoint to point to a host other than GoDaddy's
+func (p *Provider) skipTLSVerify() bool {
+	if !p.InsecureSkipVerify || p.APIEndpoint == "" {
+		return false
+	}
+	u, err := url.Parse(p.APIEndpoint)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	for _, domain := range []string{"godaddy.com", "ote-godaddy.com"} {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Provider) getHTTPClient() *http.Client {
+	timeout := p.HTTPTimeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	if p.HTTPClient != nil {
+		if p.HTTPClient.Timeout != 0 {
+			return p.HTTPClient
+		}
+		// Copy the client so the caller's client is never modified; the
+		// transport and its connection pool are still shared
+		client := *p.HTTPClient
+		client.Timeout = timeout
+		return &client
+	}
+	client := &http.Client{
+		Timeout: timeout,
+	}
+	var proxy *url.URL
+	if p.Proxy != "" {
+		proxy, _ = parseProxyURL(p.Proxy)
+	}
+	if insecure := p.skipTLSVerify(); proxy != nil || insecure {
+		client.Transport = sharedTransport(proxy, insecure)
+	}
+	return client
+}
+
+// getCredentials returns the "key:secret" pair used for authentication,
+// preferring APIKey and APISecret over APIToken when both are set
+func (p *Provider) getCredentials() string {
+	if p.APIKey != "" && p.APISecret != "" {
+		return p.APIKey + ":" + p.APISecret
+	}
+	return p.APIToken
+}
+
+func (p *Provider) setCommonHeaders(req *http.Request) {
+	// Set first, so that the headers below take precedence
+	for key, value := range p.ExtraHeaders {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+	req.Header.Set("Authorization", "sso-key "+p.getCredentials())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("User-Agent", p.getUserAgent())
+	if p.ShopperID != "" {
+		req.Header.Set("X-Shopper-Id", p.ShopperID)
+	}
+}
+
+// redacted replaces credentials in headers, logs and error messages
+const redacted = "[REDACTED]"
+
+// redactHeaders returns a copy of the headers that is safe to log, with the
+// credentials in the Authorization and Proxy-Authorization headers masked.
+// Use it wherever headers are printed.
+func redactHeaders(header http.Header) http.Header {
+	clone := header.Clone()
+	if clone.Get("Authorization") != "" {
+		clone.Set("Authorization", "sso-key "+redacted)
+	}
+	if clone.Get("Proxy-Authorization") != "" {
+		clone.Set("Proxy-Authorization", redacted)
+	}
+	return clone
+}
+
+// redactedError is an error whose message has the credentials masked, while
+// errors.Is and errors.As still see the original error
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError masks the credentials of the provider in the message of err,
+// e.g. for transport errors of custom clients that include request headers
+func (p *Provider) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	masked := msg
+	for _, secret := range []string{p.getCredentials(), p.APIToken, p.APISecret} {
+		if secret != "" {
+			masked = strings.ReplaceAll(masked, secret, redacted)
+		}
+	}
+	// The secret half of the token on its own
+	if _, secret, ok := strings.Cut(p.APIToken, ":"); ok && secret != "" {
+		masked = strings.ReplaceAll(masked, secret, redacted)
+	}
+
+	if masked == msg {
+		return err
+	}
+	return &redactedError{msg: masked, err: err}
+}
+
+func (p *Provider) getUserAgent() string {
+	if p.UserAgent != "" {
+		return p.UserAgent
+	}
+	return DefaultUserAgent
+}
+
+func (p *Provider) getLogger() *slog.Logger {
+	if p.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return p.Logger
+}
+
+func (p *Provider) getMaxRetries() int {
+	if p.MaxRetries < 0 {
+		return 0
+	}
+	if p.MaxRetries == 0 {
+		return defaultMaxRetries
+	}
+	return p.MaxRetries
+}
+
+func (p *Provider) getMaxTTL() time.Duration {
+	if p.MaxTTL <= 0 {
+		return defaultMaxTTL
+	}
+	return p.MaxTTL
+}
+
+func (p *Provider) getMinTTL() time.Duration {
+	if p.MinTTL <= 0 {
+		return defaultMinTTL
+	}
+	return p.MinTTL
+}
+
+func (p *Provider) enforceMinTTL() bool {
+	return p.EnforceMinTTL == nil || *p.EnforceMinTTL
+}
+
+func (p *Provider) getPageSize() int {
+	if p.PageSize <= 0 {
+		return defaultPageSize
+	}
+	return p.PageSize
+}
+
+// doRequest sends a request to the GoDaddy API and returns the response along
+// with its fully read body. Requests that are rate limited (HTTP 429) or that
+// fail with a server error (HTTP 5xx) are retried up to MaxRetries times,
+// honoring the Retry-After header when present and otherwise backing off
+// exponentially with jitter; see isRetryable and retryDelay. Client errors (HTTP 4xx) are
+// never retried. Checking the status code of the final response is left to
+// the caller.
+//
+// In DryRun mode, mutating requests are logged and answered with a synthetic
+// successful response instead of being sent.
+func (p *Provider) doRequest(ctx context.Context, method, url string, body []byte) (*http.Response, []byte, error) {
+	if err := p.validateConfig(); err != nil {
+		return nil, nil, err
+	}
+
+	client := p.getHTTPClient()
+	maxRetries := p.getMaxRetries()
+	logger := p.getLogger()
+	var provisioningSince time.Time
+
+	for attempt := 0; ; attempt++ {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		for key, values := range requestHeaders(ctx) {
+			req.Header[key] = values
+		}
+		p.setCommonHeaders(req)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		if p.DryRun && method != http.MethodGet {
+			return p.dryRunResponse(req, body), nil, nil
+		}
+
+		if limiter := p.getLimiter(); limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, nil, fmt.Errorf("rate limit: %w", err)
+			}
+		}
+
+		// Cap the attempt, not the whole call; a shorter deadline of ctx still wins
+		cancel := context.CancelFunc(func() {})
+		if p.PerRequestTimeout > 0 {
+			var reqCtx context.Context
+			reqCtx, cancel = context.WithTimeout(ctx, p.PerRequestTimeout)
+			req = req.WithContext(reqCtx)
+		}
+
+		logger.DebugContext(ctx, "sending GoDaddy API request",
+			"method", method, "url", url, "attempt", attempt+1, "headers", redactHeaders(req.Header))
+
+		if p.OnRequest != nil {
+			p.OnRequest(req)
+		}
+		start := p.getNow()
+
+		resp, err := client.Do(req)
+		if err != nil {
+			cancel()
+			err = p.redactError(err)
+			logger.DebugContext(ctx, "GoDaddy API request failed",
+				"method", method, "url", url, "error", err)
+			if p.OnResponse != nil {
+				p.OnResponse(req, nil, p.getNow().Sub(start), err)
+			}
+			return nil, nil, fmt.Errorf("failed to execute request: %w", err)
+		}
+
+		// Read response body for error handling
+		bodyBytes, err := readBody(resp)
+		resp.Body.Close()
+		cancel()
+		if p.OnResponse != nil {
+			p.OnResponse(req, resp, p.getNow().Sub(start), err)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read response body: %w", p.redactError(err))
+		}
+
+		logger.DebugContext(ctx, "received GoDaddy API response",
+			"method", method, "url", url, "status", resp.StatusCode)
+		p.getState().updateRateLimit(resp.Header, p.getNow())
+
+		delay := p.retryDelay(resp, attempt, p.getNow())
+		if !isRetryable(method, resp.StatusCode) || attempt >= maxRetries {
+			if p.ProvisioningRetryWindow <= 0 || method == http.MethodGet || !isProvisioning(resp, bodyBytes) {
+				return resp, bodyBytes, nil
+			}
+			if provisioningSince.IsZero() {
+				provisioningSince = p.getNow()
+			}
+			remaining := p.ProvisioningRetryWindow - p.getNow().Sub(provisioningSince)
+			if remaining <= 0 {
+				return resp, bodyBytes, nil
+			}
+			delay = min(delay, remaining)
+		}
+
+		logger.DebugContext(ctx, "retrying GoDaddy API request",
+			"method", method, "url", url, "status", resp.StatusCode, "attempt", attempt+1, "max_retries", maxRetries, "delay", delay)
+		if err := p.sleepContext(ctx, delay); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// isSuccess reports whether a response status indicates success. GoDaddy
+// documents 200 for most requests and 204 for deletions, but some endpoints
+// answer deletions with 200 as well, so any 2xx status is accepted.
+func isSuccess(status int) bool {
+	return status >= 200 && status <= 299
+}
+
+// isRetryable reports whether a response with the given status code is worth
+// retrying. Rate limiting (HTTP 429) is always transient. Server errors (HTTP
+// 5xx) are retried only for idempotent methods: a PATCH appends records, and
+// GoDaddy may have applied it before failing, so repeating it could create
+// duplicates.
+func isRetryable(method string, status int) bool {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return true
+	case status >= 500 && status <= 599:
+		return method != http.MethodPatch && method != http.MethodPost
+	default:
+		return false
+	}
+}
+
+// isProvisioning reports whether a response rejects a request because the DNS
+// of the domain is still being provisioned, as after a transfer, rather than
+// because the request is invalid. GoDaddy answers both with HTTP 409 or 422,
+// but names the rejected fields of invalid requests.
+func isProvisioning(resp *http.Response, body []byte) bool {
+	if resp.StatusCode != http.StatusConflict && resp.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	return len(newAPIError(resp, body).Fields) == 0
+}
+
+// readBody reads the body of a response, decompressing it if GoDaddy sent it
+// gzip-encoded. Setting Accept-Encoding explicitly disables the transparent
+// decompression of http.Transport, which custom transports may not offer.
+func readBody(resp *http.Response) ([]byte, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return io.ReadAll(resp.Body)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if errors.Is(err, io.EOF) {
+		// Empty body
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response: %w", err)
+	}
+	defer gz.Close()
+
+	return io.ReadAll(gz)
+}
+
+// dryRunResponse logs a mutating request instead of sending it and returns the
+// response GoDaddy sends on success
+func (p *Provider) dryRunResponse(req *http.Request, body []byte) *http.Response {
+	p.getLogger().InfoContext(req.Context(), "dry run: skipping GoDaddy API request",
+		"method", req.Method, "url", req.URL.String(), "body", string(body))
+
+	status := http.StatusOK
+	if req.Method == http.MethodDelete {
+		status = http.StatusNoContent
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
+
+// retryDelay returns how long to wait before the next attempt, preferring the
+// Retry-After header sent by GoDaddy over exponential backoff. A Retry-After
+// date is relative to now. The backoff delay doubles from RetryBaseDelay with
+// every attempt up to RetryMaxDelay, and unless DisableRetryJitter is set, a
+// random delay between zero and the backoff delay is returned.
+func (p *Provider) retryDelay(resp *http.Response, attempt int, now time.Time) time.Duration {
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			if delay := date.Sub(now); delay > 0 {
+				return delay
+			}
+			return 0
+		}
+	}
+
+	// Compare before shifting, as the shifted delay may overflow
+	delay := p.getRetryMaxDelay()
+	if base := p.getRetryBaseDelay(); attempt < 63 && base <= delay>>attempt {
+		delay = base << attempt
+	}
+
+	if p.DisableRetryJitter {
+		return delay
+	}
+	return rand.N(delay + 1)
+}
+
+func (p *Provider) getRetryBaseDelay() time.Duration {
+	if p.RetryBaseDelay <= 0 {
+		return defaultRetryBaseDelay
+	}
+	return p.RetryBaseDelay
+}
+
+func (p *Provider) getRetryMaxDelay() time.Duration {
+	if p.RetryMaxDelay <= 0 {
+		return defaultRetryMaxDelay
+	}
+	return p.RetryMaxDelay
+}
+
+func (p *Provider) getNow() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// sleepContext waits for the given duration with the injected sleep function,
+// falling back to the package-level sleepContext
+func (p *Provider) sleepContext(ctx context.Context, d time.Duration) error {
+	if p.sleep != nil {
+		return p.sleep(ctx, d)
+	}
+	return sleepContext(ctx, d)
+}
+
+// sleepContext waits for the given duration, returning early with the context
+// error if the context is done before then
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// runConcurrently calls fn for every index in [0, n) and reports which calls
+// succeeded. With MaxConcurrency greater than one, up to MaxConcurrency calls
+// run at the same time and the first failure cancels the outstanding ones;
+// otherwise the calls run one after the other. The context is checked before
+// every call, so no new call is started once it is done.
+func (p *Provider) runConcurrently(ctx context.Context, n int, fn func(ctx context.Context, i int) error) ([]bool, error) {
+	done := make([]bool, n)
+
+	if p.MaxConcurrency <= 1 {
+		for i := 0; i < n; i++ {
+			if err := ctx.Err(); err != nil {
+				return done, err
+			}
+			if err := fn(ctx, i); err != nil {
+				return done, err
+			}
+			done[i] = true
+		}
+		return done, nil
+	}
+
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	jobs := make(chan int)
+
+	for range min(p.MaxConcurrency, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := fn(workCtx, i)
+
+				mu.Lock()
+				if err != nil {
+					// Skip the cancellations caused by an earlier failure
+					if len(errs) == 0 || !errors.Is(err, context.Canceled) {
+						errs = append(errs, err)
+					}
+					cancel()
+				} else {
+					done[i] = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case <-workCtx.Done():
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return done, err
+	}
+	if len(errs) > 0 {
+		return done, errors.Join(errs...)
+	}
+	return done, nil
+}
+
+// requestHeadersKey is the context key of the headers added to a request
+type requestHeadersKey struct{}
+
+// ContextWithHeaders returns a context adding the given headers to every
+// request sent to GoDaddy with it, e.g. an X-Request-Id to correlate the
+// requests of an operation with the caller's traces. Headers set by the
+// provider itself, such as Authorization, Accept and User-Agent, take
+// precedence.
+func ContextWithHeaders(ctx context.Context, header http.Header) context.Context {
+	merged := requestHeaders(ctx).Clone()
+	if merged == nil {
+		merged = http.Header{}
+	}
+	for key, values := range header {
+		merged.Del(key)
+		for _, value := range values {
+			merged.Add(key, value)
+		}
+	}
+	return context.WithValue(ctx, requestHeadersKey{}, merged)
+}
+
+// withRequestHeader returns a context making doRequest set the given header
+func withRequestHeader(ctx context.Context, key, value string) context.Context {
+	header := requestHeaders(ctx).Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(key, value)
+	return context.WithValue(ctx, requestHeadersKey{}, header)
+}
+
+// requestHeaders returns the headers added to requests sent with the context
+func requestHeaders(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeadersKey{}).(http.Header)
+	return header
+}
+
+// doZoneRequest sends a request modifying the records of a zone, dropping the
+// cached records of the zone. With EnableOptimisticConcurrency, the ETag of the
+// last listing of the zone is sent as If-Match, and replaced by the ETag of the
+// response once the zone has changed. If a successful response has no ETag,
+// the zone is listed again to get the one of the changed zone, so that the
+// following requests of an operation are protected as well. The requests to
+// a domain are sent one at a time, as each needs the ETag left by the previous
+// one, while those to other domains go ahead.
+func (p *Provider) doZoneRequest(ctx context.Context, zone, method, url string, body []byte) (*http.Response, []byte, error) {
+	domain := getDomain(zone)
+	state := p.getState()
+	defer state.dropCachedRecords(domain)
+
+	if !p.EnableOptimisticConcurrency {
+		return p.doRequest(ctx, method, url, body)
+	}
+
+	writeMu := state.writeMu(domain)
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	etag := state.etag(domain)
+	reqCtx := ctx
+	if etag != "" {
+		reqCtx = withRequestHeader(ctx, "If-Match", etag)
+	}
+
+	resp, bodyBytes, err := p.doRequest(reqCtx, method, url, body)
+	if err != nil {
+		return resp, bodyBytes, err
+	}
+	switch {
+	case isSuccess(resp.StatusCode) && etag != "" && resp.Header.Get("ETag") == "" && !p.DryRun:
+		p.refreshETag(ctx, zone)
+	case isSuccess(resp.StatusCode) || resp.StatusCode == http.StatusPreconditionFailed:
+		state.setETag(domain, resp.Header.Get("ETag"))
+	}
+	return resp, bodyBytes, err
+}
+
+// refreshETag lists the first page of the zone to store its current ETag. If
+// that fails, the ETag is forgotten, so the following requests are sent
+// without If-Match rather than failing with ErrConflict. A change made by
+// someone else between the write and the listing is part of the ETag stored,
+// and so goes undetected by the following requests.
+func (p *Provider) refreshETag(ctx context.Context, zone string) {
+	domain := getDomain(zone)
+	url := fmt.Sprintf("%s/v1/domains/%s/records?offset=0&limit=%d", p.getApiHost(), domain, p.getPageSize())
+
+	resp, _, err := p.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil || !isSuccess(resp.StatusCode) {
+		p.getState().setETag(domain, "")
+		return
+	}
+	p.getState().setETag(domain, resp.Header.Get("ETag"))
+}
+
+// providerState is the mutable state shared by the requests of a provider
+type providerState struct {
+	mu sync.Mutex
+
+	// etags holds the ETag of the last listing of each domain
+	etags map[string]string
+
+	// writeMus serialize the modifying requests to each domain sent with
+	// Provider.EnableOptimisticConcurrency, see doZoneRequest
+	writeMus map[string]*sync.Mutex
+
+	// records caches the records of each zone, see Provider.CacheTTL
+	records map[string]cachedRecords
+
+	// limiter throttles the requests of the provider, see Provider.RateLimit
+	limiter *rate.Limiter
+
+	// listings holds the last page of records returned for each listing URL,
+	// see Provider.EnableConditionalGet
+	listings map[string]cachedListing
+
+	// zoneInfo caches the results of GetZoneInfo for each domain
+	zoneInfo map[string]cachedZoneInfo
+
+	// rateLimit holds the rate limit GoDaddy reported last, if any
+	rateLimit *RateLimitStatus
+}
+
+// cachedListing is a page of records along with the ETag GoDaddy returned for
+// it, see Provider.EnableConditionalGet
+type cachedListing struct {
+	etag    string
+	records []godaddyRecord
+}
+
+// cachedZoneInfo is the metadata of a domain along with its expiry
+type cachedZoneInfo struct {
+	info    ZoneInfo
+	expires time.Time
+}
+
+// cachedRecords are the records of a zone along with their expiry
+type cachedRecords struct {
+	records []godaddyRecord
+	expires time.Time
+}
+
+// stateMu guards the lazy initialization of Provider.state
+var stateMu sync.Mutex
+
+// getState returns the state of the provider, creating it on first use
+func (p *Provider) getState() *providerState {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if p.state == nil {
+		p.state = &providerState{}
+	}
+	return p.state
+}
+
+// getLimiter returns the rate limiter shared by the requests of the provider,
+// creating it on first use, or nil if RateLimit is not set
+func (p *Provider) getLimiter() *rate.Limiter {
+	if p.RateLimit <= 0 {
+		return nil
+	}
+
+	state := p.getState()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.limiter == nil {
+		interval := p.RateLimitInterval
+		if interval <= 0 {
+			interval = defaultRateLimitInterval
+		}
+		// A burst of one spreads the requests evenly over the interval
+		state.limiter = rate.NewLimiter(rate.Every(interval/time.Duration(p.RateLimit)), 1)
+	}
+	return state.limiter
+}
+
+// RateLimitStatus is the rate limit GoDaddy reported in the X-RateLimit-*
+// headers of a response
+type RateLimitStatus struct {
+	// Limit is the number of requests allowed in the current window
+	Limit int
+
+	// Remaining is the number of requests left in the current window
+	Remaining int
+
+	// Reset is when the current window ends, or the zero time if GoDaddy did
+	// not say
+	Reset time.Time
+
+	// Updated is when the response reporting the status was received
+	Updated time.Time
+}
+
+// RateLimitStatus returns the rate limit reported by the last response from
+// GoDaddy that carried X-RateLimit-Limit and X-RateLimit-Remaining headers,
+// and false if none did yet. Callers throttling adaptively can slow down as
+// Remaining approaches zero instead of waiting for HTTP 429.
+func (p *Provider) RateLimitStatus() (RateLimitStatus, bool) {
+	state := p.getState()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.rateLimit == nil {
+		return RateLimitStatus{}, false
+	}
+	return *state.rateLimit, true
+}
+
+// updateRateLimit stores the rate limit reported by the headers of a response
+// received at now, if they report one. X-RateLimit-Reset may be a Unix time
+// or a number of seconds from now.
+func (s *providerState) updateRateLimit(header http.Header, now time.Time) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	status := RateLimitStatus{Limit: limit, Remaining: remaining, Updated: now}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset >= 0 {
+		// Durations of over a year are Unix times
+		if reset > 365*24*60*60 {
+			status.Reset = time.Unix(reset, 0)
+		} else {
+			status.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimit = &status
+}
+
+// writeMu returns the mutex serializing the modifying requests to a domain,
+// creating it on first use
+func (s *providerState) writeMu(domain string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.writeMus == nil {
+		s.writeMus = make(map[string]*sync.Mutex)
+	}
+	if s.writeMus[domain] == nil {
+		s.writeMus[domain] = &sync.Mutex{}
+	}
+	return s.writeMus[domain]
+}
+
+func (s *providerState) etag(domain string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.etags[domain]
+}
+
+// setETag stores the ETag of a domain, forgetting it if etag is empty
+func (s *providerState) setETag(domain, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if etag == "" {
+		delete(s.etags, domain)
+		return
+	}
+	if s.etags == nil {
+		s.etags = make(map[string]string)
+	}
+	s.etags[domain] = etag
+}
+
+// cachedListing returns the last page of records returned for a listing URL
+func (s *providerState) cachedListing(url string) (cachedListing, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listing, ok := s.listings[url]
+	listing.records = append([]godaddyRecord(nil), listing.records...)
+	return listing, ok
+}
+
+// cacheListing stores a page of records returned for a listing URL, forgetting
+// it if etag is empty
+func (s *providerState) cacheListing(url, etag string, records []godaddyRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if etag == "" {
+		delete(s.listings, url)
+		return
+	}
+	if s.listings == nil {
+		s.listings = make(map[string]cachedListing)
+	}
+	s.listings[url] = cachedListing{etag: etag, records: append([]godaddyRecord(nil), records...)}
+}
+
+// cachedRecords returns a copy of the cached records of a zone if they have
+// not expired at now
+func (s *providerState) cachedRecords(zone string, now time.Time) ([]godaddyRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.records[zone]
+	if !ok || !now.Before(entry.expires) {
+		return nil, false
+	}
+	return append([]godaddyRecord(nil), entry.records...), true
+}
+
+func (s *providerState) cacheRecords(zone string, records []godaddyRecord, expires time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.records == nil {
+		s.records = make(map[string]cachedRecords)
+	}
+	s.records[zone] = cachedRecords{
+		records: append([]godaddyRecord(nil), records...),
+		expires: expires,
+	}
+}
+
+// dropCachedRecords drops the cached records of the domain and of the zones
+// delegated below it, which share its records
+func (s *providerState) dropCachedRecords(domain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for zone := range s.records {
+		if strings.EqualFold(zone, domain) || strings.HasSuffix(strings.ToLower(zone), "."+strings.ToLower(domain)) {
+			delete(s.records, zone)
+		}
+	}
+}
+
+// cachedZoneInfo returns a copy of the cached metadata of a domain if it has
+// not expired at now
+func (s *providerState) cachedZoneInfo(domain string, now time.Time) (ZoneInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.zoneInfo[domain]
+	if !ok || !now.Before(entry.expires) {
+		return ZoneInfo{}, false
+	}
+	info := entry.info
+	info.NameServers = append([]string(nil), info.NameServers...)
+	return info, true
+}
+
+func (s *providerState) cacheZoneInfo(domain string, info ZoneInfo, expires time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.zoneInfo == nil {
+		s.zoneInfo = make(map[string]cachedZoneInfo)
+	}
+	info.NameServers = append([]string(nil), info.NameServers...)
+	s.zoneInfo[domain] = cachedZoneInfo{info: info, expires: expires}
+}
@@ -0,0 +1,1052 @@
+package godaddy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxRetriesConfiguration(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		expected   int
+	}{
+		{"Default retries", 0, defaultMaxRetries},
+		{"Custom retries", 5, 5},
+		{"Retries disabled", -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Provider{MaxRetries: tt.maxRetries}
+			if result := p.getMaxRetries(); result != tt.expected {
+				t.Errorf("getMaxRetries() = %d; expected %d", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Run("Retry-After seconds", func(t *testing.T) {
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+		if delay := (&Provider{}).retryDelay(resp, 0, time.Now()); delay != 7*time.Second {
+			t.Errorf("retryDelay() = %v; expected 7s", delay)
+		}
+	})
+
+	t.Run("Retry-After date in the past", func(t *testing.T) {
+		date := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{date}}}
+		if delay := (&Provider{}).retryDelay(resp, 0, time.Now()); delay != 0 {
+			t.Errorf("retryDelay() = %v; expected 0", delay)
+		}
+	})
+
+	t.Run("Retry-After date in the future", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		date := now.Add(90 * time.Second).Format(http.TimeFormat)
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{date}}}
+		if delay := (&Provider{}).retryDelay(resp, 0, now); delay != 90*time.Second {
+			t.Errorf("retryDelay() = %v; expected 90s", delay)
+		}
+	})
+
+	t.Run("Full jitter", func(t *testing.T) {
+		resp := &http.Response{Header: http.Header{}}
+		provider := Provider{}
+		for attempt := 0; attempt < 70; attempt++ {
+			limit := min(defaultRetryBaseDelay<<min(attempt, 30), defaultRetryMaxDelay)
+			delay := provider.retryDelay(resp, attempt, time.Now())
+			if delay < 0 || delay > limit {
+				t.Errorf("attempt %d: retryDelay() = %v; expected between 0 and %v", attempt, delay, limit)
+			}
+		}
+	})
+
+	t.Run("Configured delays without jitter", func(t *testing.T) {
+		resp := &http.Response{Header: http.Header{}}
+		provider := Provider{RetryBaseDelay: 100 * time.Millisecond, RetryMaxDelay: time.Second, DisableRetryJitter: true}
+		expected := []time.Duration{
+			100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond,
+			time.Second, time.Second,
+		}
+		for attempt, want := range expected {
+			if delay := provider.retryDelay(resp, attempt, time.Now()); delay != want {
+				t.Errorf("attempt %d: retryDelay() = %v; expected %v", attempt, delay, want)
+			}
+		}
+		if delay := provider.retryDelay(resp, 100, time.Now()); delay != time.Second {
+			t.Errorf("attempt 100: retryDelay() = %v; expected the cap of 1s", delay)
+		}
+	})
+}
+
+func TestSleepContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err := sleepContext(ctx, time.Hour)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("sleepContext did not return immediately after cancellation")
+	}
+}
+
+func TestDoRequestRetriesRateLimited(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	resp, body, err := provider.doRequest(context.Background(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "[]" {
+		t.Errorf("Unexpected response: status %d, body %s", resp.StatusCode, body)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoRequestGivesUpAfterMaxRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, MaxRetries: 2}
+	resp, _, err := provider.doRequest(context.Background(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoRequestRetriesServerErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		status        int
+		expectedCalls int
+		expectedCode  int
+	}{
+		{"GET retried on 503", http.MethodGet, http.StatusServiceUnavailable, 3, http.StatusOK},
+		{"PUT retried on 500", http.MethodPut, http.StatusInternalServerError, 3, http.StatusOK},
+		{"DELETE retried on 502", http.MethodDelete, http.StatusBadGateway, 3, http.StatusOK},
+		{"PATCH not retried on 503", http.MethodPatch, http.StatusServiceUnavailable, 1, http.StatusServiceUnavailable},
+		{"GET not retried on 400", http.MethodGet, http.StatusBadRequest, 1, http.StatusBadRequest},
+		{"PUT not retried on 422", http.MethodPut, http.StatusUnprocessableEntity, 1, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls < 3 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte("[]"))
+			}))
+			defer server.Close()
+
+			var delays []time.Duration
+			provider := Provider{
+				APIToken:    "test:secret",
+				APIEndpoint: server.URL,
+				sleep: func(ctx context.Context, d time.Duration) error {
+					delays = append(delays, d)
+					return nil
+				},
+			}
+			resp, _, err := provider.doRequest(context.Background(), tt.method, server.URL, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if resp.StatusCode != tt.expectedCode {
+				t.Errorf("Status mismatch: expected %d, got %d", tt.expectedCode, resp.StatusCode)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+			if len(delays) != tt.expectedCalls-1 {
+				t.Errorf("Expected %d backoff delays, got %d", tt.expectedCalls-1, len(delays))
+			}
+		})
+	}
+}
+
+func TestProvisioningRetry(t *testing.T) {
+	const provisioning = `{"code":"CONFLICT","message":"DNS for the domain is not ready yet"}`
+	const invalid = `{"code":"INVALID_BODY","message":"Request body doesn't fulfill schema","fields":[{"path":"records[0].data","code":"UNEXPECTED_TYPE","message":"is not a string"}]}`
+
+	tests := []struct {
+		name          string
+		method        string
+		window        time.Duration
+		status        int
+		body          string
+		failures      int
+		expectedCalls int
+		expectedCode  int
+	}{
+		{"409 followed by success", http.MethodPatch, time.Minute, http.StatusConflict, provisioning, 2, 3, http.StatusOK},
+		{"422 followed by success", http.MethodPut, time.Minute, http.StatusUnprocessableEntity, provisioning, 1, 2, http.StatusOK},
+		{"Validation errors not retried", http.MethodPut, time.Minute, http.StatusUnprocessableEntity, invalid, 1, 1, http.StatusUnprocessableEntity},
+		{"Not retried without a window", http.MethodPatch, 0, http.StatusConflict, provisioning, 1, 1, http.StatusConflict},
+		{"GET not retried", http.MethodGet, time.Minute, http.StatusConflict, provisioning, 1, 1, http.StatusConflict},
+		{"Retried for the window only", http.MethodDelete, 10 * time.Second, http.StatusConflict, provisioning, 100, 11, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tt.failures {
+					w.WriteHeader(tt.status)
+					w.Write([]byte(tt.body))
+					return
+				}
+				w.Write([]byte("[]"))
+			}))
+			defer server.Close()
+
+			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			provider := Provider{
+				APIToken:                "test:secret",
+				APIEndpoint:             server.URL,
+				ProvisioningRetryWindow: tt.window,
+				RetryBaseDelay:          time.Second,
+				RetryMaxDelay:           time.Second,
+				DisableRetryJitter:      true,
+				now:                     func() time.Time { return now },
+				sleep: func(ctx context.Context, d time.Duration) error {
+					now = now.Add(d)
+					return nil
+				},
+			}
+			resp, _, err := provider.doRequest(context.Background(), tt.method, server.URL, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if resp.StatusCode != tt.expectedCode {
+				t.Errorf("Status mismatch: expected %d, got %d", tt.expectedCode, resp.StatusCode)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestIsSuccess(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected bool
+	}{
+		{http.StatusOK, true},
+		{http.StatusNoContent, true},
+		{http.StatusAccepted, true},
+		{http.StatusMultipleChoices, false},
+		{http.StatusNotFound, false},
+		{http.StatusInternalServerError, false},
+	}
+
+	for _, tt := range tests {
+		if got := isSuccess(tt.status); got != tt.expected {
+			t.Errorf("isSuccess(%d) mismatch: expected %v, got %v", tt.status, tt.expected, got)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		method   string
+		status   int
+		expected bool
+	}{
+		{http.MethodGet, http.StatusTooManyRequests, true},
+		{http.MethodPatch, http.StatusTooManyRequests, true},
+		{http.MethodGet, http.StatusInternalServerError, true},
+		{http.MethodPut, http.StatusGatewayTimeout, true},
+		{http.MethodDelete, http.StatusServiceUnavailable, true},
+		{http.MethodPatch, http.StatusServiceUnavailable, false},
+		{http.MethodGet, http.StatusOK, false},
+		{http.MethodGet, http.StatusNotFound, false},
+		{http.MethodPut, http.StatusUnprocessableEntity, false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetryable(tt.method, tt.status); got != tt.expected {
+			t.Errorf("isRetryable(%s, %d) mismatch: expected %v, got %v", tt.method, tt.status, tt.expected, got)
+		}
+	}
+}
+
+func TestAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		expected string
+	}{
+		{
+			name:     "Combined token",
+			provider: Provider{APIToken: "key:secret"},
+			expected: "sso-key key:secret",
+		},
+		{
+			name:     "Separate key and secret",
+			provider: Provider{APIKey: "key", APISecret: "secret"},
+			expected: "sso-key key:secret",
+		},
+		{
+			name:     "Separate key and secret take precedence",
+			provider: Provider{APIToken: "old:token", APIKey: "key", APISecret: "secret"},
+			expected: "sso-key key:secret",
+		},
+		{
+			name:     "Key without secret falls back to token",
+			provider: Provider{APIToken: "old:token", APIKey: "key"},
+			expected: "sso-key old:token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "https://api.godaddy.com", nil)
+			tt.provider.setCommonHeaders(req)
+			if auth := req.Header.Get("Authorization"); auth != tt.expected {
+				t.Errorf("Authorization = %s; expected %s", auth, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDoRequestLogging(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	provider := Provider{APIToken: "key:supersecret", APIEndpoint: server.URL, Logger: logger}
+
+	if _, _, err := provider.doRequest(context.Background(), http.MethodGet, server.URL+"/v1/domains", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	logs := buf.String()
+	for _, expected := range []string{"method=GET", "/v1/domains", "status=429", "status=200", "retrying", "attempt=2"} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("Expected logs to contain %q, got:\n%s", expected, logs)
+		}
+	}
+	if strings.Contains(logs, "supersecret") {
+		t.Errorf("Logs leaked the API secret:\n%s", logs)
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "sso-key key:secret")
+	header.Set("Accept", "application/json")
+	header.Set("Proxy-Authorization", "Basic dXNlcjpwYXNz")
+
+	redacted := redactHeaders(header)
+	if auth := redacted.Get("Authorization"); auth != "sso-key [REDACTED]" {
+		t.Errorf("Authorization = %s; expected it to be redacted", auth)
+	}
+	if auth := redacted.Get("Proxy-Authorization"); auth != "[REDACTED]" {
+		t.Errorf("Proxy-Authorization = %s; expected it to be redacted", auth)
+	}
+	if accept := redacted.Get("Accept"); accept != "application/json" {
+		t.Errorf("Accept = %s; expected it to be kept", accept)
+	}
+	if auth := header.Get("Authorization"); auth != "sso-key key:secret" {
+		t.Errorf("Original headers were modified")
+	}
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRedactErrors(t *testing.T) {
+	errTransport := errors.New("transport failure")
+	// A transport that echoes the request headers in its error, as some
+	// proxies and instrumented clients do
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("%w: request with headers %v", errTransport, req.Header)
+	})}
+
+	tests := []struct {
+		name     string
+		provider Provider
+	}{
+		{"API token", Provider{APIToken: "key:supersecret"}},
+		{"API key and secret", Provider{APIKey: "key", APISecret: "supersecret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hookErr error
+			provider := tt.provider
+			provider.APIEndpoint = "http://godaddy.invalid"
+			provider.HTTPClient = client
+			provider.OnResponse = func(req *http.Request, resp *http.Response, elapsed time.Duration, err error) {
+				hookErr = err
+			}
+
+			_, err := provider.GetRecords(context.Background(), "example.com.")
+			if err == nil {
+				t.Fatalf("Expected an error")
+			}
+			for _, e := range []error{err, hookErr} {
+				if strings.Contains(e.Error(), "supersecret") {
+					t.Errorf("Error leaked the API secret: %v", e)
+				}
+				if !strings.Contains(e.Error(), "[REDACTED]") {
+					t.Errorf("Expected the credentials to be redacted, got %v", e)
+				}
+			}
+			if !errors.Is(err, errTransport) {
+				t.Errorf("Expected the transport error to be wrapped, got %v", err)
+			}
+		})
+	}
+}
+
+func TestShopperIDHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		expected []string
+	}{
+		{
+			name:     "Without shopper ID",
+			provider: Provider{APIToken: "key:secret"},
+			expected: nil,
+		},
+		{
+			name:     "With shopper ID",
+			provider: Provider{APIToken: "key:secret", ShopperID: "123456"},
+			expected: []string{"123456"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Values("X-Shopper-Id")
+			}))
+			defer server.Close()
+
+			if _, _, err := tt.provider.doRequest(context.Background(), http.MethodGet, server.URL, nil); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(header) != len(tt.expected) || (len(header) == 1 && header[0] != tt.expected[0]) {
+				t.Errorf("X-Shopper-Id = %v; expected %v", header, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	for _, concurrency := range []int{0, 4} {
+		t.Run("MaxConcurrency="+strconv.Itoa(concurrency), func(t *testing.T) {
+			provider := Provider{MaxConcurrency: concurrency}
+
+			done, err := provider.runConcurrently(context.Background(), 10, func(ctx context.Context, i int) error {
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for i, ok := range done {
+				if !ok {
+					t.Errorf("Call %d was not reported as done", i)
+				}
+			}
+
+			var calls atomic.Int32
+			done, err = provider.runConcurrently(context.Background(), 10, func(ctx context.Context, i int) error {
+				calls.Add(1)
+				if i == 0 {
+					return errFailed
+				}
+				// Block until the failure cancels the outstanding calls
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			if !errors.Is(err, errFailed) {
+				t.Errorf("Expected the failure to be returned, got %v", err)
+			}
+			if errors.Is(err, context.Canceled) {
+				t.Errorf("Expected the cancellations caused by the failure to be dropped, got %v", err)
+			}
+			if done[0] {
+				t.Errorf("Failed call was reported as done")
+			}
+			if n := calls.Load(); concurrency <= 1 && n != 1 {
+				t.Errorf("Expected the serial run to stop after the failure, got %d calls", n)
+			}
+		})
+	}
+}
+
+func TestRequestHooks(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	var requests []string
+	var statuses []int
+	provider := Provider{
+		APIToken:    "test:secret",
+		APIEndpoint: server.URL,
+		OnRequest: func(req *http.Request) {
+			requests = append(requests, req.Method+" "+req.URL.Path)
+		},
+		OnResponse: func(req *http.Request, resp *http.Response, elapsed time.Duration, err error) {
+			if err != nil || resp == nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if elapsed <= 0 {
+				t.Errorf("Expected a positive duration, got %v", elapsed)
+			}
+			statuses = append(statuses, resp.StatusCode)
+		},
+	}
+
+	if _, _, err := provider.doRequest(context.Background(), http.MethodGet, server.URL+"/v1/domains", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(requests) != 2 || requests[0] != "GET /v1/domains" {
+		t.Errorf("Unexpected requests: %v", requests)
+	}
+	if len(statuses) != 2 || statuses[0] != http.StatusTooManyRequests || statuses[1] != http.StatusOK {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+}
+
+func TestResponseHookOnFailure(t *testing.T) {
+	var hookErr error
+	provider := Provider{
+		APIToken:    "test:secret",
+		APIEndpoint: "http://127.0.0.1:0",
+		OnResponse: func(req *http.Request, resp *http.Response, elapsed time.Duration, err error) {
+			if resp != nil {
+				t.Errorf("Expected no response, got status %d", resp.StatusCode)
+			}
+			hookErr = err
+		},
+	}
+
+	if _, _, err := provider.doRequest(context.Background(), http.MethodGet, "http://127.0.0.1:0/v1/domains", nil); err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if hookErr == nil {
+		t.Errorf("Expected OnResponse to receive the error")
+	}
+}
+
+func TestGzipResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if encoding := r.Header.Get("Accept-Encoding"); encoding != "gzip" {
+			t.Errorf("Accept-Encoding = %s; expected gzip", encoding)
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`[{"type":"A","name":"www","data":"192.0.2.1","ttl":600}]`))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	records, err := provider.GetRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 1 || records[0].RR().Data != "192.0.2.1" {
+		t.Errorf("Unexpected records: %v", records)
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte("hello"))
+	gz.Close()
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		expected string
+		wantErr  bool
+	}{
+		{"Plain body", "", []byte("hello"), "hello", false},
+		{"Gzip body", "gzip", compressed.Bytes(), "hello", false},
+		{"Empty gzip body", "gzip", nil, "", false},
+		{"Corrupt gzip body", "gzip", []byte("not gzip"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header: http.Header{"Content-Encoding": []string{tt.encoding}},
+				Body:   io.NopCloser(bytes.NewReader(tt.body)),
+			}
+			body, err := readBody(resp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readBody() error = %v; expected error: %v", err, tt.wantErr)
+			}
+			if string(body) != tt.expected {
+				t.Errorf("readBody() = %q; expected %q", body, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDoRequestBackoffWithFakeClock(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	provider := Provider{
+		APIToken:    "test:secret",
+		APIEndpoint: server.URL,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}
+
+	start := time.Now()
+	if _, _, err := provider.doRequest(context.Background(), http.MethodGet, server.URL, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected no real sleeping, took %v", elapsed)
+	}
+
+	if len(delays) != 3 {
+		t.Fatalf("Expected 3 delays, got %v", delays)
+	}
+	for attempt, delay := range delays {
+		expected := defaultRetryBaseDelay << attempt
+		if delay < 0 || delay > expected {
+			t.Errorf("attempt %d: delay = %v; expected between 0 and %v", attempt, delay, expected)
+		}
+	}
+}
+
+func TestUserAgentHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		expected string
+	}{
+		{"Default", Provider{}, "libdns-godaddy/1.0"},
+		{"Overridden", Provider{UserAgent: "mytool/2.0"}, "mytool/2.0"},
+		{"Combined with the default", Provider{UserAgent: "mytool/2.0 " + DefaultUserAgent}, "mytool/2.0 libdns-godaddy/1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "https://api.godaddy.com", nil)
+			tt.provider.setCommonHeaders(req)
+			if ua := req.Header.Get("User-Agent"); ua != tt.expected {
+				t.Errorf("User-Agent = %s; expected %s", ua, tt.expected)
+			}
+		})
+	}
+}
+
+func TestContextWithHeaders(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := ContextWithHeaders(context.Background(), http.Header{
+		"x-request-id":  {"req-1"},
+		"Authorization": {"Bearer other"},
+		"Accept":        {"text/html"},
+	})
+	ctx = ContextWithHeaders(ctx, http.Header{"Traceparent": {"00-trace-span-01"}})
+
+	if _, _, err := provider.doRequest(ctx, http.MethodGet, server.URL, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"X-Request-Id":  "req-1",
+		"Traceparent":   "00-trace-span-01",
+		"Authorization": "sso-key test:secret",
+		"Accept":        "application/json",
+	}
+	for key, value := range expected {
+		if got := received.Get(key); got != value {
+			t.Errorf("%s header mismatch: expected %s, got %s", key, value, got)
+		}
+	}
+}
+
+func TestExtraHeaders(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(
+		WithAPIToken("test:secret"),
+		WithAPIEndpoint(server.URL),
+		WithShopperID("12345"),
+		WithExtraHeaders(map[string]string{
+			"X-Gateway-Key": "gateway-secret",
+			"x-tenant":      "static",
+			"Authorization": "Bearer other",
+			"X-Shopper-Id":  "67890",
+			"User-Agent":    "gateway/1.0",
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx := ContextWithHeaders(context.Background(), http.Header{"X-Tenant": {"per-request"}})
+
+	if _, _, err := provider.doRequest(ctx, http.MethodGet, server.URL, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"X-Gateway-Key": "gateway-secret",
+		"X-Tenant":      "per-request",
+		"Authorization": "sso-key test:secret",
+		"X-Shopper-Id":  "12345",
+		"User-Agent":    DefaultUserAgent,
+	}
+	for key, value := range expected {
+		if got := received.Get(key); got != value {
+			t.Errorf("%s header mismatch: expected %s, got %s", key, value, got)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	t.Run("Spreads requests", func(t *testing.T) {
+		provider := Provider{
+			APIToken:          "test:secret",
+			APIEndpoint:       server.URL,
+			RateLimit:         10,
+			RateLimitInterval: 200 * time.Millisecond,
+			MaxConcurrency:    4,
+		}
+
+		start := time.Now()
+		_, err := provider.runConcurrently(context.Background(), 5, func(ctx context.Context, i int) error {
+			_, _, err := provider.doRequest(ctx, http.MethodGet, server.URL, nil)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// The first request is sent right away, the other four 20ms apart
+		if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+			t.Errorf("Expected requests to be throttled, 5 requests took %s", elapsed)
+		}
+	})
+
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		calls.Store(0)
+		provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, RateLimit: 1, RateLimitInterval: time.Hour}
+		if _, _, err := provider.doRequest(context.Background(), http.MethodGet, server.URL, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		_, _, err := provider.doRequest(ctx, http.MethodGet, server.URL, nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("Expected 1 call, got %d", n)
+		}
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+		if limiter := provider.getLimiter(); limiter != nil {
+			t.Errorf("Expected no limiter, got %v", limiter)
+		}
+	})
+}
+
+func TestRateLimitStatus(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, now: func() time.Time { return now }}
+
+	if _, ok := provider.RateLimitStatus(); ok {
+		t.Fatal("Expected no rate limit status before the first request")
+	}
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected RateLimitStatus
+	}{
+		{
+			name:     "Reset in seconds",
+			header:   http.Header{"X-Ratelimit-Limit": {"60"}, "X-Ratelimit-Remaining": {"42"}, "X-Ratelimit-Reset": {"30"}},
+			expected: RateLimitStatus{Limit: 60, Remaining: 42, Reset: now.Add(30 * time.Second), Updated: now},
+		},
+		{
+			name:     "Reset as Unix time",
+			header:   http.Header{"X-Ratelimit-Limit": {"60"}, "X-Ratelimit-Remaining": {"41"}, "X-Ratelimit-Reset": {"1704110460"}},
+			expected: RateLimitStatus{Limit: 60, Remaining: 41, Reset: time.Unix(1704110460, 0), Updated: now},
+		},
+		{
+			name:     "Without reset",
+			header:   http.Header{"X-Ratelimit-Limit": {"60"}, "X-Ratelimit-Remaining": {"40"}},
+			expected: RateLimitStatus{Limit: 60, Remaining: 40, Updated: now},
+		},
+		{
+			name:     "Missing headers keep the last status",
+			header:   http.Header{},
+			expected: RateLimitStatus{Limit: 60, Remaining: 40, Updated: now},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header = tt.header
+			if _, _, err := provider.doRequest(context.Background(), http.MethodGet, server.URL, nil); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			status, ok := provider.RateLimitStatus()
+			if !ok {
+				t.Fatal("Expected a rate limit status")
+			}
+			if status.Limit != tt.expected.Limit || status.Remaining != tt.expected.Remaining ||
+				!status.Reset.Equal(tt.expected.Reset) || !status.Updated.Equal(tt.expected.Updated) {
+				t.Errorf("Status mismatch: expected %+v, got %+v", tt.expected, status)
+			}
+		})
+	}
+}
+
+func TestPerRequestTimeout(t *testing.T) {
+	var deadlines []time.Time
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		deadline, _ := req.Context().Deadline()
+		deadlines = append(deadlines, deadline)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("[]")),
+		}, nil
+	})}
+
+	tests := []struct {
+		name              string
+		perRequestTimeout time.Duration
+		ctxTimeout        time.Duration
+		expected          time.Duration
+	}{
+		{"HTTPTimeout only", 0, 0, 30 * time.Second},
+		{"Per-request timeout only", time.Second, 0, time.Second},
+		{"Per-request timeout shorter", time.Second, time.Hour, time.Second},
+		{"Context deadline shorter", time.Hour, time.Second, time.Second},
+		{"Context deadline only", 0, time.Second, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadlines = nil
+			ctx := context.Background()
+			if tt.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.ctxTimeout)
+				defer cancel()
+			}
+			provider := Provider{
+				APIToken:          "test:secret",
+				APIEndpoint:       "http://godaddy.invalid",
+				HTTPClient:        client,
+				PerRequestTimeout: tt.perRequestTimeout,
+			}
+
+			start := time.Now()
+			for range 2 {
+				if _, _, err := provider.doRequest(ctx, http.MethodGet, "http://godaddy.invalid/v1/domains", nil); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			for i, deadline := range deadlines {
+				// Each request gets its own deadline, so allow for the time spent
+				if remaining := deadline.Sub(start); remaining < tt.expected-100*time.Millisecond || remaining > tt.expected+100*time.Millisecond {
+					t.Errorf("Request %d: expected a deadline in %s, got %s", i, tt.expected, remaining)
+				}
+			}
+		})
+	}
+
+	t.Run("Slow request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}))
+		defer server.Close()
+
+		provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, PerRequestTimeout: 20 * time.Millisecond}
+		start := time.Now()
+		_, _, err := provider.doRequest(context.Background(), http.MethodGet, server.URL, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected the request to time out quickly, took %s", elapsed)
+		}
+	})
+}
+
+func TestInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	t.Run("Applies to a custom endpoint", func(t *testing.T) {
+		provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, InsecureSkipVerify: true}
+		if _, _, err := provider.doRequest(context.Background(), http.MethodGet, server.URL, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Verifies by default", func(t *testing.T) {
+		provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+		if _, _, err := provider.doRequest(context.Background(), http.MethodGet, server.URL, nil); err == nil {
+			t.Fatal("Expected the self-signed certificate to be rejected")
+		}
+	})
+
+	tests := []struct {
+		name     string
+		provider Provider
+		expected bool
+	}{
+		{"Custom endpoint", Provider{APIEndpoint: "https://mock.internal", InsecureSkipVerify: true}, true},
+		{"Disabled", Provider{APIEndpoint: "https://mock.internal"}, false},
+		{"Production", Provider{InsecureSkipVerify: true}, false},
+		{"OTE", Provider{Environment: EnvironmentOTE, InsecureSkipVerify: true}, false},
+		{"Production endpoint", Provider{APIEndpoint: "https://api.godaddy.com", InsecureSkipVerify: true}, false},
+		{"OTE endpoint", Provider{APIEndpoint: "https://API.OTE-GoDaddy.com.", InsecureSkipVerify: true}, false},
+		{"Lookalike host", Provider{APIEndpoint: "https://notgodaddy.com", InsecureSkipVerify: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.provider.skipTLSVerify(); got != tt.expected {
+				t.Errorf("skipTLSVerify() mismatch: expected %v, got %v", tt.expected, got)
+			}
+			transport, _ := tt.provider.getHTTPClient().Transport.(*http.Transport)
+			insecure := transport != nil && transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify
+			if insecure != tt.expected {
+				t.Errorf("Transport skips verification: expected %v, got %v", tt.expected, insecure)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package godaddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// ComputeDiff compares the desired records with the records currently in the
+// zone and returns the changes needed to converge. Only the types and names
+// that appear in desired are considered, and records are compared by
+// RecordKey:
+//
+//   - add holds the desired records that are not in the zone
+//   - update holds the desired records that are in the zone with another TTL
+//   - remove holds the records in the zone that are not desired
+//
+// The zone is fetched once. Desired records are converted as they would be
+// written, so e.g. a zero TTL compares as the TTL that would be sent.
+func (p *Provider) ComputeDiff(ctx context.Context, zone string, desired []libdns.Record) (add, update, remove []libdns.Record, err error) {
+	currentRecords, err := p.getRecords(ctx, zone)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get current records: %w", err)
+	}
+
+	return p.diffRecords(zone, convertToLibdnsRecords(p.filterSystemRecords(zone, currentRecords)), desired)
+}
+
+// diffRecords computes the changes turning the current records into the
+// desired ones, restricted to the types and names of the desired records
+func (p *Provider) diffRecords(zone string, current, desired []libdns.Record) (add, update, remove []libdns.Record, err error) {
+	groups := make(map[string]bool)
+	wanted := make(map[string]bool)
+	var desiredRecords []libdns.Record
+	var desiredTTLs []int
+
+	for _, record := range desired {
+		gr, err := p.convertRecord(record, zone)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to convert record: %w", err)
+		}
+
+		key := RecordKey(zone, record)
+		if wanted[key] {
+			continue
+		}
+		wanted[key] = true
+		// Keyed on the libdns name, which unlike gr.Name includes the service
+		// and protocol of SRV records, as SetRecords keeps other services
+		groups[strings.ToUpper(gr.Type)+"/"+getRecordName(zone, record.RR().Name)] = true
+		desiredRecords = append(desiredRecords, record)
+		desiredTTLs = append(desiredTTLs, gr.TTL)
+	}
+
+	live := make(map[string]libdns.RR)
+	for _, record := range current {
+		rr := record.RR()
+		if !groups[strings.ToUpper(rr.Type)+"/"+getRecordName(zone, rr.Name)] {
+			continue
+		}
+		key := RecordKey(zone, record)
+		live[key] = rr
+		if !wanted[key] {
+			remove = append(remove, record)
+		}
+	}
+
+	for i, record := range desiredRecords {
+		rr, ok := live[RecordKey(zone, record)]
+		switch {
+		case !ok:
+			add = append(add, record)
+		case int(rr.TTL/time.Second) != desiredTTLs[i]:
+			update = append(update, record)
+		}
+	}
+
+	return add, update, remove, nil
+}
+
+// Snapshot holds the records of a zone at a point in time, see
+// SnapshotRecords and DiffSnapshots
+type Snapshot struct {
+	// Zone is the zone the records belong to
+	Zone string
+
+	// Taken is when the records were fetched
+	Taken time.Time
+
+	// Records are the records of the zone
+	Records []libdns.Record
+}
+
+// SnapshotRecords fetches the records of the zone (or serves them from the
+// cache, see CacheTTL) as a snapshot to compare with later ones using
+// DiffSnapshots. GoDaddy has no change feed, so polling snapshots is how
+// changes made elsewhere can be detected.
+func (p *Provider) SnapshotRecords(ctx context.Context, zone string) (Snapshot, error) {
+	records, err := p.GetRecords(ctx, zone)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Zone: zone, Taken: p.getNow(), Records: records}, nil
+}
+
+// DiffSnapshots compares two snapshots of the same zone, matching records by
+// RecordKey:
+//
+//   - added holds the records of newer that are not in older
+//   - modified holds the records of newer that are in older with another TTL
+//   - removed holds the records of older that are not in newer
+//
+// As the data is part of the key, a record whose value changed appears as
+// removed with its old value and added with its new one.
+func DiffSnapshots(older, newer Snapshot) (added, modified, removed []libdns.Record) {
+	zone := newer.Zone
+	if zone == "" {
+		zone = older.Zone
+	}
+
+	previous := make(map[string]libdns.RR)
+	for _, record := range older.Records {
+		key := RecordKey(zone, record)
+		if _, ok := previous[key]; !ok {
+			previous[key] = record.RR()
+		}
+	}
+
+	current := make(map[string]bool)
+	for _, record := range newer.Records {
+		key := RecordKey(zone, record)
+		if current[key] {
+			continue
+		}
+		current[key] = true
+
+		rr, ok := previous[key]
+		switch {
+		case !ok:
+			added = append(added, record)
+		case rr.TTL != record.RR().TTL:
+			modified = append(modified, record)
+		}
+	}
+
+	for _, record := range older.Records {
+		key := RecordKey(zone, record)
+		if !current[key] {
+			current[key] = true
+			removed = append(removed, record)
+		}
+	}
+
+	return added, modified, removed
+}
@@ -0,0 +1,185 @@
+package godaddy
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestComputeDiff(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+		{Type: "A", Name: "www", Data: "192.0.2.2", TTL: 600},
+		{Type: "TXT", Name: "www", Data: "keep", TTL: 600},
+		{Type: "TXT", Name: "_acme-challenge", Data: "token", TTL: 3600},
+		{Type: "MX", Name: "@", Data: "10 mail.example.com", TTL: 3600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+	calls := 0
+	provider.OnRequest = func(*http.Request) { calls++ }
+
+	add, update, remove, err := provider.ComputeDiff(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.3")},
+		libdns.TXT{Name: "_acme-challenge", Text: "token", TTL: 10 * time.Minute},
+		libdns.MX{Name: "@", Preference: 10, Target: "mail.example.com.", TTL: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected the zone to be fetched once, got %d requests", calls)
+	}
+	if len(add) != 1 || add[0].RR().Data != "192.0.2.3" {
+		t.Errorf("Unexpected records to add: %v", add)
+	}
+	if len(update) != 1 || update[0].RR().Type != "TXT" || update[0].RR().TTL != 10*time.Minute {
+		t.Errorf("Unexpected records to update: %v", update)
+	}
+	// The TXT record of www is left alone, as no TXT record is desired for www
+	if len(remove) != 1 || remove[0].RR().Data != "192.0.2.2" {
+		t.Errorf("Unexpected records to remove: %v", remove)
+	}
+}
+
+func TestComputeDiffSRV(t *testing.T) {
+	srv := func(service string, port uint16, target string) libdns.SRV {
+		return libdns.SRV{Service: service, Transport: "tcp", Name: "@", TTL: time.Hour, Priority: 10, Weight: 5, Port: port, Target: target}
+	}
+	tests := []struct {
+		name           string
+		desired        libdns.Record
+		expectedAdd    []string
+		expectedRemove []string
+	}{
+		{
+			name:    "Unchanged",
+			desired: srv("sip", 5060, "sip.example.com"),
+		},
+		{
+			name:           "Changed port",
+			desired:        srv("sip", 5061, "sip.example.com"),
+			expectedAdd:    []string{"SRV/_sip._tcp/10 5 5061 sip.example.com"},
+			expectedRemove: []string{"SRV/_sip._tcp/10 5 5060 sip.example.com"},
+		},
+		{
+			name:        "Other service at the same name",
+			desired:     srv("imap", 143, "imap.example.com"),
+			expectedAdd: []string{"SRV/_imap._tcp/10 5 143 imap.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := &fakeZone{records: []godaddyRecord{
+				{Type: "SRV", Name: "@", Data: "sip.example.com", TTL: 3600, Priority: 10, Weight: 5, Port: 5060, Service: "_sip", Protocol: "_tcp"},
+				{Type: "SRV", Name: "@", Data: "xmpp.example.com", TTL: 3600, Priority: 10, Weight: 5, Port: 5222, Service: "_xmpp", Protocol: "_tcp"},
+			}}
+			server := newFakeZoneServer(t, zone)
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+			add, update, remove, err := provider.ComputeDiff(context.Background(), "example.com.", []libdns.Record{tt.desired})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			keys := func(records []libdns.Record) []string {
+				var keys []string
+				for _, record := range records {
+					keys = append(keys, RecordKey("example.com.", record))
+				}
+				return keys
+			}
+			if got := keys(add); !slices.Equal(got, tt.expectedAdd) {
+				t.Errorf("Add mismatch: expected %v, got %v", tt.expectedAdd, got)
+			}
+			if len(update) != 0 {
+				t.Errorf("Expected no records to update, got %v", update)
+			}
+			if got := keys(remove); !slices.Equal(got, tt.expectedRemove) {
+				t.Errorf("Remove mismatch: expected %v, got %v", tt.expectedRemove, got)
+			}
+		})
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	older := Snapshot{Zone: "example.com.", Records: []libdns.Record{
+		libdns.Address{Name: "www", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "api", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.2")},
+		libdns.TXT{Name: "@", TTL: time.Hour, Text: "v=spf1 -all"},
+		libdns.MX{Name: "@", TTL: time.Hour, Preference: 10, Target: "mail.example.com."},
+	}}
+	newer := Snapshot{Zone: "example.com.", Records: []libdns.Record{
+		libdns.Address{Name: "www.example.com.", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "api", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.3")},
+		libdns.TXT{Name: "@", TTL: 2 * time.Hour, Text: "v=spf1 -all"},
+		libdns.MX{Name: "@", TTL: time.Hour, Preference: 10, Target: "mail.example.com"},
+		libdns.CNAME{Name: "blog", TTL: time.Hour, Target: "example.com."},
+	}}
+
+	added, modified, removed := DiffSnapshots(older, newer)
+
+	keys := func(records []libdns.Record) []string {
+		var keys []string
+		for _, record := range records {
+			keys = append(keys, RecordKey("example.com.", record))
+		}
+		return keys
+	}
+	tests := []struct {
+		name     string
+		records  []libdns.Record
+		expected []string
+	}{
+		{"Added", added, []string{"A/api/192.0.2.3", "CNAME/blog/example.com"}},
+		{"Modified", modified, []string{"TXT/@/v=spf1 -all"}},
+		{"Removed", removed, []string{"A/api/192.0.2.2"}},
+	}
+	for _, tt := range tests {
+		if got := keys(tt.records); !slices.Equal(got, tt.expected) {
+			t.Errorf("%s mismatch: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+	if ttl := modified[0].RR().TTL; ttl != 2*time.Hour {
+		t.Errorf("Expected the modified record to carry the new TTL, got %v", ttl)
+	}
+
+	if added, modified, removed := DiffSnapshots(newer, newer); len(added)+len(modified)+len(removed) != 0 {
+		t.Errorf("Expected no changes between identical snapshots, got %v, %v, %v", added, modified, removed)
+	}
+}
+
+func TestSnapshotRecords(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, now: func() time.Time { return now }}
+
+	older, err := provider.SnapshotRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if older.Zone != "example.com." || !older.Taken.Equal(now) || len(older.Records) != 1 {
+		t.Errorf("Snapshot mismatch: got %+v", older)
+	}
+
+	zone.records = append(zone.records, godaddyRecord{Type: "TXT", Name: "www", Data: "hello", TTL: 600})
+	newer, err := provider.SnapshotRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if added, _, _ := DiffSnapshots(older, newer); len(added) != 1 || added[0].RR().Type != "TXT" {
+		t.Errorf("Expected the TXT record to be added, got %v", added)
+	}
+}
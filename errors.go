@@ -0,0 +1,297 @@
+package godaddy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedRecordType is returned (wrapped) when a record of a type that
+// GoDaddy cannot manage, such as PTR, is written.
+var ErrUnsupportedRecordType = errors.New("record type not supported by GoDaddy")
+
+// ErrZoneNotFound is returned (wrapped) when GoDaddy responds with HTTP 404 to
+// a request on a zone as a whole, such as listing its records, meaning the
+// domain is not on the account or is not using GoDaddy's nameservers.
+var ErrZoneNotFound = errors.New("zone not found on the GoDaddy account")
+
+// ErrConflict is returned (wrapped) when a modifying request is rejected with
+// HTTP 412 because the zone changed since it was last read, see
+// Provider.EnableOptimisticConcurrency.
+var ErrConflict = errors.New("zone changed since it was last read")
+
+// ErrRecordNotFound is returned (wrapped) by UpdateRecords for records whose
+// type and name do not exist in the zone.
+var ErrRecordNotFound = errors.New("record does not exist")
+
+// ErrCNAMEAtApex is returned (wrapped) when a CNAME record is written at the
+// zone apex, where DNS allows no CNAME next to the SOA and NS records.
+var ErrCNAMEAtApex = errors.New("a CNAME is not allowed at the zone apex")
+
+// ErrApexNS is returned (wrapped) when NS records are written at the apex of
+// a registered domain. These are the domain's own nameservers, which GoDaddy
+// manages through the domain's nameserver settings rather than its records.
+var ErrApexNS = errors.New("the nameservers of the domain cannot be changed through its DNS records")
+
+// ErrRecordLimit is returned (wrapped) when writing records would leave more
+// records of a type in the zone than Provider.RecordLimits allows.
+var ErrRecordLimit = errors.New("record limit exceeded")
+
+// ErrUnauthorized is returned (wrapped) when GoDaddy responds with HTTP 401,
+// meaning it did not accept the API key and secret.
+var ErrUnauthorized = errors.New("GoDaddy rejected the API credentials")
+
+// APIError is returned (wrapped) when the GoDaddy API responds with an
+// unexpected status code. Use errors.As to inspect it:
+//
+//	var apiErr *godaddy.APIError
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+//		// domain not found
+//	}
+//
+// It also supports errors.Is against a partially filled APIError, matching
+// on every non-zero field of the target:
+//
+//	errors.Is(err, &godaddy.APIError{StatusCode: http.StatusUnauthorized})
+type APIError struct {
+	// Method and URL identify the request that failed, with any credentials
+	// in the URL redacted
+	Method string
+	URL    string
+
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+
+	// Code is GoDaddy's machine-readable error code, e.g. "NOT_FOUND"
+	Code string
+
+	// Message is GoDaddy's human-readable error message, or the raw response
+	// body if it could not be parsed
+	Message string
+
+	// Fields lists the paths of the request fields GoDaddy rejected, e.g. "records.0.data"
+	Fields []string
+
+	// FieldErrors details why each field in Fields was rejected
+	FieldErrors []FieldError
+}
+
+// FieldError describes a request field GoDaddy rejected, as listed in the
+// "fields" array of its validation errors
+type FieldError struct {
+	// Path is the path of the field in the request body, e.g. "records.0.data"
+	Path string
+
+	// Code is GoDaddy's machine-readable error code for the field
+	Code string
+
+	// Message is GoDaddy's description of the problem, e.g. "is not a string"
+	Message string
+
+	// Record identifies the rejected record as "name/type", e.g. "www/A",
+	// when the path refers to a record sent in the request
+	Record string
+}
+
+// String returns a human-readable description of the field error, e.g.
+// "data is invalid for record www/A: is not a string"
+func (f FieldError) String() string {
+	msg := f.Path + " is invalid"
+	if _, field, ok := parseFieldPath(f.Path); ok && f.Record != "" {
+		msg = field + " is invalid for record " + f.Record
+	}
+	if f.Message != "" {
+		msg += ": " + f.Message
+	}
+	return msg
+}
+
+// parseFieldPath extracts the index of the record and the name of the field
+// from the path of a rejected field, e.g. 0 and "data" from "records.0.data"
+// or "records[0].data"
+func parseFieldPath(path string) (int, string, bool) {
+	parts := strings.FieldsFunc(path, func(r rune) bool {
+		return r == '.' || r == '[' || r == ']'
+	})
+	for i, part := range parts {
+		index, err := strconv.Atoi(part)
+		if err != nil || index < 0 {
+			continue
+		}
+		if i+1 >= len(parts) {
+			return 0, "", false
+		}
+		return index, strings.Join(parts[i+1:], "."), true
+	}
+	return 0, "", false
+}
+
+// godaddyError represents an error body as returned by GoDaddy API
+type godaddyError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Fields  []struct {
+		Path    string `json:"path"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"fields"`
+}
+
+// newAPIError builds an APIError from a response and its body
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if req := resp.Request; req != nil && req.URL != nil {
+		apiErr.Method = req.Method
+		apiErr.URL = req.URL.Redacted()
+	}
+
+	// Proxies and GoDaddy's maintenance page answer with HTML, e.g. a 503
+	// during maintenance, which would only clutter the message
+	if isHTML(resp, body) {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			apiErr.Message += ", GoDaddy may be down for maintenance"
+		}
+		return apiErr
+	}
+
+	var ge godaddyError
+	if err := json.Unmarshal(body, &ge); err != nil || (ge.Code == "" && ge.Message == "") {
+		apiErr.Message = strings.TrimSpace(string(body))
+		return apiErr
+	}
+
+	apiErr.Code = ge.Code
+	apiErr.Message = ge.Message
+	for _, field := range ge.Fields {
+		apiErr.Fields = append(apiErr.Fields, field.Path)
+		apiErr.FieldErrors = append(apiErr.FieldErrors, FieldError{
+			Path:    field.Path,
+			Code:    field.Code,
+			Message: field.Message,
+		})
+	}
+	return apiErr
+}
+
+// isHTML reports whether a response has an HTML body, going by its
+// Content-Type or, if it has none, by the body itself
+func isHTML(resp *http.Response, body []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err == nil {
+		return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	}
+	start := strings.ToLower(strings.TrimSpace(string(body[:min(len(body), 64)])))
+	return strings.HasPrefix(start, "<!doctype html") || strings.HasPrefix(start, "<html")
+}
+
+// identifyRecords fills in the Record of the field errors referring to one of
+// the records sent in the request
+func (e *APIError) identifyRecords(sent []godaddyRecord) {
+	for i, field := range e.FieldErrors {
+		if index, _, ok := parseFieldPath(field.Path); ok && index < len(sent) {
+			e.FieldErrors[i].Record = sent[index].Name + "/" + sent[index].Type
+		}
+	}
+}
+
+// newZoneError builds the error for an unexpected response to a request on a
+// zone as a whole, i.e. not scoped to a type and name, like newRecordsError
+// but also wrapping ErrZoneNotFound on 404, which then means the domain is
+// missing.
+func newZoneError(resp *http.Response, body []byte, zone string, sent ...godaddyRecord) error {
+	err := newRecordsError(resp, body, zone, sent...)
+	if resp.StatusCode == http.StatusNotFound && !errors.Is(err, ErrZoneNotFound) {
+		return fmt.Errorf("%s: %w (%w)", describeDomain(zone), ErrZoneNotFound, err)
+	}
+	return err
+}
+
+// newRecordsError builds the error for an unexpected response to a request on
+// the records of a zone, wrapping ErrUnauthorized on 401 or ErrConflict on 412
+// as well as the APIError. A 404 to a request scoped to a type and name may
+// only mean that there are no such records, so it wraps ErrZoneNotFound only
+// if GoDaddy says the domain is unknown. The records sent in the request, if
+// any, are used to name the records of rejected fields.
+func newRecordsError(resp *http.Response, body []byte, zone string, sent ...godaddyRecord) error {
+	apiErr := newAPIError(resp, body)
+	apiErr.identifyRecords(sent)
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return newUnauthorizedError(apiErr)
+	case http.StatusNotFound:
+		if apiErr.Code == "UNKNOWN_DOMAIN" {
+			return fmt.Errorf("%s: %w (%w)", describeDomain(zone), ErrZoneNotFound, apiErr)
+		}
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("%s: %w (%w)", describeDomain(zone), ErrConflict, apiErr)
+	}
+	return apiErr
+}
+
+// describeDomain names the registered domain of a zone in errors, along with
+// the zone if it is delegated below the domain
+func describeDomain(zone string) string {
+	domain := getDomain(zone)
+	if getZonePrefix(zone) != "" {
+		domain += " (registered domain of " + strings.TrimSuffix(zone, ".") + ")"
+	}
+	return domain
+}
+
+// newRequestError builds the error for an unexpected response to a request
+// that is not about a zone, wrapping ErrUnauthorized on 401 as well as the
+// APIError
+func newRequestError(resp *http.Response, body []byte) error {
+	apiErr := newAPIError(resp, body)
+	if resp.StatusCode == http.StatusUnauthorized {
+		return newUnauthorizedError(apiErr)
+	}
+	return apiErr
+}
+
+// newUnauthorizedError wraps ErrUnauthorized with the usual causes of a 401,
+// as GoDaddy's own message does not tell them apart
+func newUnauthorizedError(apiErr *APIError) error {
+	return fmt.Errorf("%w: check that the credentials are a key and secret in the form \"key:secret\", "+
+		"that the key was created for the environment in use (OTE keys only work with EnvironmentOTE, "+
+		"production keys only in production) and that it has not been revoked (%w)", ErrUnauthorized, apiErr)
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("status %d", e.StatusCode)
+	if e.Method != "" && e.URL != "" {
+		msg = e.Method + " " + e.URL + ": " + msg
+	}
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if len(e.FieldErrors) > 0 {
+		var fields []string
+		for _, field := range e.FieldErrors {
+			fields = append(fields, field.String())
+		}
+		msg += " (" + strings.Join(fields, "; ") + ")"
+	} else if len(e.Fields) > 0 {
+		msg += " (fields: " + strings.Join(e.Fields, ", ") + ")"
+	}
+	return msg
+}
+
+// Is reports whether target is an *APIError whose non-zero StatusCode and
+// Code match those of e
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	return (t.StatusCode == 0 || t.StatusCode == e.StatusCode) &&
+		(t.Code == "" || t.Code == e.Code)
+}
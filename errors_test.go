@@ -0,0 +1,592 @@
+package godaddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected APIError
+		message  string
+	}{
+		{
+			name:   "JSON error body",
+			status: http.StatusNotFound,
+			body:   `{"code":"NOT_FOUND","message":"Domain not found"}`,
+			expected: APIError{
+				StatusCode: http.StatusNotFound,
+				Code:       "NOT_FOUND",
+				Message:    "Domain not found",
+			},
+			message: "status 404 (NOT_FOUND): Domain not found",
+		},
+		{
+			name:   "JSON error body with fields",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"code":"INVALID_BODY","message":"Request body doesn't fulfill schema","fields":[{"path":"records.0.data","code":"UNEXPECTED_TYPE","message":"is not a string"}]}`,
+			expected: APIError{
+				StatusCode: http.StatusUnprocessableEntity,
+				Code:       "INVALID_BODY",
+				Message:    "Request body doesn't fulfill schema",
+				Fields:     []string{"records.0.data"},
+			},
+			message: "status 422 (INVALID_BODY): Request body doesn't fulfill schema (records.0.data is invalid: is not a string)",
+		},
+		{
+			name:   "Non-JSON error body",
+			status: http.StatusBadGateway,
+			body:   "Bad Gateway\n",
+			expected: APIError{
+				StatusCode: http.StatusBadGateway,
+				Message:    "Bad Gateway",
+			},
+			message: "status 502: Bad Gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := newAPIError(&http.Response{StatusCode: tt.status}, []byte(tt.body))
+
+			if apiErr.StatusCode != tt.expected.StatusCode || apiErr.Code != tt.expected.Code || apiErr.Message != tt.expected.Message {
+				t.Errorf("newAPIError() = %+v; expected %+v", apiErr, tt.expected)
+			}
+			if fmt.Sprint(apiErr.Fields) != fmt.Sprint(tt.expected.Fields) {
+				t.Errorf("Fields = %v; expected %v", apiErr.Fields, tt.expected.Fields)
+			}
+			if apiErr.Error() != tt.message {
+				t.Errorf("Error() = %q; expected %q", apiErr.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestHTMLErrorBody(t *testing.T) {
+	const page = "<!DOCTYPE html>\n<html><head><title>Maintenance</title></head><body><h1>We'll be back soon</h1></body></html>"
+
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		message     string
+	}{
+		{"Maintenance page", http.StatusServiceUnavailable, "text/html; charset=utf-8", page, "Service Unavailable, GoDaddy may be down for maintenance"},
+		{"Proxy error page", http.StatusBadGateway, "text/html", "<html><body>Bad Gateway</body></html>", "Bad Gateway"},
+		{"HTML without Content-Type", http.StatusServiceUnavailable, "", page, "Service Unavailable, GoDaddy may be down for maintenance"},
+		{"Plain text", http.StatusServiceUnavailable, "text/plain", "upstream connect error\n", "upstream connect error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				} else {
+					// Keep the server from sniffing a Content-Type
+					w.Header()["Content-Type"] = nil
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := Provider{
+				APIToken:    "test:secret",
+				APIEndpoint: server.URL,
+				MaxRetries:  1,
+				sleep:       func(ctx context.Context, d time.Duration) error { return nil },
+			}
+			_, err := provider.GetRecords(context.Background(), "example.com.")
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected an APIError, got %v", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.message {
+				t.Errorf("APIError mismatch: expected %d %q, got %d %q", tt.status, tt.message, apiErr.StatusCode, apiErr.Message)
+			}
+			if strings.Contains(err.Error(), "<") {
+				t.Errorf("Expected no HTML in the error, got %v", err)
+			}
+			if calls != 2 {
+				t.Errorf("Expected the request to be retried once, got %d calls", calls)
+			}
+		})
+	}
+}
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		path          string
+		expectedIndex int
+		expectedField string
+		expectedOK    bool
+	}{
+		{"records.0.data", 0, "data", true},
+		{"records[2].ttl", 2, "ttl", true},
+		{"[1].name", 1, "name", true},
+		{"records.0", 0, "", false},
+		{"domain", 0, "", false},
+		{"", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		index, field, ok := parseFieldPath(tt.path)
+		if index != tt.expectedIndex || field != tt.expectedField || ok != tt.expectedOK {
+			t.Errorf("parseFieldPath(%q) = %d, %q, %v; expected %d, %q, %v",
+				tt.path, index, field, ok, tt.expectedIndex, tt.expectedField, tt.expectedOK)
+		}
+	}
+}
+
+func TestValidationErrorNamesRecords(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{
+			"code": "INVALID_BODY",
+			"message": "Request body doesn't fulfill schema, see details in ` + "`fields`" + `",
+			"fields": [
+				{"path": "records.1.data", "code": "UNEXPECTED_TYPE", "message": "is not a valid IPv4 address"},
+				{"path": "records.1.ttl", "code": "OUT_OF_RANGE", "message": "must be at least 600"}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	_, err := provider.SetRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.2")},
+	})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	for _, expected := range []string{
+		"data is invalid for record www/A: is not a valid IPv4 address",
+		"ttl is invalid for record www/A: must be at least 600",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected the error to contain %q, got %v", expected, err)
+		}
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got %T", err)
+	}
+	if len(apiErr.FieldErrors) != 2 {
+		t.Fatalf("Expected 2 field errors, got %+v", apiErr.FieldErrors)
+	}
+	field := apiErr.FieldErrors[0]
+	if field.Path != "records.1.data" || field.Code != "UNEXPECTED_TYPE" || field.Record != "www/A" {
+		t.Errorf("Unexpected field error: %+v", field)
+	}
+}
+
+func TestAPIErrorReturnedFromRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"code":"UNABLE_TO_AUTHENTICATE","message":"Unauthorized : Could not authenticate API key/secret"}`))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	_, err := provider.GetRecords(context.Background(), "example.com.")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got %T", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Code != "UNABLE_TO_AUTHENTICATE" {
+		t.Errorf("Unexpected APIError: %+v", apiErr)
+	}
+
+	if !errors.Is(err, &APIError{StatusCode: http.StatusUnauthorized}) {
+		t.Errorf("Expected errors.Is to match on status code")
+	}
+	if !errors.Is(err, &APIError{Code: "UNABLE_TO_AUTHENTICATE"}) {
+		t.Errorf("Expected errors.Is to match on code")
+	}
+	if errors.Is(err, &APIError{StatusCode: http.StatusNotFound}) {
+		t.Errorf("Expected errors.Is not to match a different status code")
+	}
+}
+
+func TestErrorsIdentifyRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`[{"type":"TXT","name":"www","data":"hello","ttl":600}]`))
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code":"INVALID_BODY","message":"Request body doesn't fulfill schema"}`))
+	}))
+	defer server.Close()
+
+	// Credentials in the endpoint must not leak into errors
+	endpoint := strings.Replace(server.URL, "http://", "http://user:password@", 1)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: endpoint}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		call     func() error
+		expected []string
+	}{
+		{
+			name: "AppendRecords",
+			call: func() error {
+				_, err := provider.AppendRecords(ctx, "example.com.", []libdns.Record{libdns.TXT{Name: "www", Text: "hi"}})
+				return err
+			},
+			expected: []string{"PATCH http://user:xxxxx@", "/v1/domains/example.com/records: status 422", "www.example.com (TXT)"},
+		},
+		{
+			name: "DeleteRecords",
+			call: func() error {
+				_, err := provider.DeleteRecords(ctx, "example.com.", []libdns.Record{libdns.TXT{Name: "www"}})
+				return err
+			},
+			expected: []string{"DELETE http://user:xxxxx@", "/v1/domains/example.com/records/TXT/www: status 422", "www.example.com (TXT)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected the error to contain %q, got %v", expected, err)
+				}
+			}
+			if strings.Contains(err.Error(), "password") {
+				t.Errorf("Expected the password to be redacted, got %v", err)
+			}
+		})
+	}
+}
+
+func TestZoneNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"UNKNOWN_DOMAIN","message":"The given domain is not registered, or does not have a zone file"}`))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+	records := []libdns.Record{libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")}}
+
+	operations := map[string]func() error{
+		"GetRecords": func() error {
+			_, err := provider.GetRecords(ctx, "missing.com.")
+			return err
+		},
+		"AppendRecords": func() error {
+			_, err := provider.AppendRecords(ctx, "missing.com.", records)
+			return err
+		},
+		"SetRecords": func() error {
+			_, err := provider.SetRecords(ctx, "missing.com.", records)
+			return err
+		},
+		"DeleteRecords": func() error {
+			_, err := provider.DeleteRecords(ctx, "missing.com.", records)
+			return err
+		},
+		"ReplaceAllRecords": func() error {
+			_, err := provider.ReplaceAllRecords(ctx, "missing.com.", records)
+			return err
+		},
+	}
+
+	for name, operation := range operations {
+		t.Run(name, func(t *testing.T) {
+			err := operation()
+			if !errors.Is(err, ErrZoneNotFound) {
+				t.Fatalf("Expected ErrZoneNotFound, got %v", err)
+			}
+			if !errors.Is(err, &APIError{StatusCode: http.StatusNotFound}) {
+				t.Errorf("Expected the APIError to be wrapped as well, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "missing.com") {
+				t.Errorf("Expected the error to name the domain, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDelegatedZoneNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/missing.com/records" {
+			t.Errorf("Unexpected request path: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"UNKNOWN_DOMAIN","message":"The given domain is not registered, or does not have a zone file"}`))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	_, err := provider.GetRecords(context.Background(), "k8s.missing.com.")
+	if !errors.Is(err, ErrZoneNotFound) {
+		t.Fatalf("Expected ErrZoneNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "missing.com (registered domain of k8s.missing.com)") {
+		t.Errorf("Expected the error to name the registered domain and the zone, got %v", err)
+	}
+}
+
+func TestRecordsNotFound(t *testing.T) {
+	// The zone has the record, but it is gone by the time it is written
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`[{"type":"A","name":"www","data":"192.0.2.1","ttl":600}]`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"NOT_FOUND","message":"The requested resource was not found"}`))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	deleted, err := provider.DeleteRecords(ctx, "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+	})
+	if err != nil {
+		t.Fatalf("Expected deleting records that are gone to succeed, got %v", err)
+	}
+	if len(deleted) != 1 {
+		t.Errorf("Expected the record to be reported as deleted, got %v", deleted)
+	}
+
+	_, err = provider.SetRecords(ctx, "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.2")},
+	})
+	if errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected a scoped 404 not to mean the zone is missing, got %v", err)
+	}
+	if !errors.Is(err, &APIError{StatusCode: http.StatusNotFound}) {
+		t.Errorf("Expected the APIError to be wrapped, got %v", err)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"code":"UNABLE_TO_AUTHENTICATE","message":"Unauthorized : Could not authenticate API key/secret"}`))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"GetRecords", func() error {
+			_, err := provider.GetRecords(ctx, "example.com.")
+			return err
+		}},
+		{"AppendRecords", func() error {
+			_, err := provider.AppendRecords(ctx, "example.com.", []libdns.Record{libdns.TXT{Name: "www", Text: "hello"}})
+			return err
+		}},
+		{"ListZones", func() error {
+			_, err := provider.ListZones(ctx)
+			return err
+		}},
+		{"Ping", func() error {
+			return provider.Ping(ctx)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, ErrUnauthorized) {
+				t.Fatalf("Expected ErrUnauthorized, got %v", err)
+			}
+			if !errors.Is(err, &APIError{StatusCode: http.StatusUnauthorized, Code: "UNABLE_TO_AUTHENTICATE"}) {
+				t.Errorf("Expected the APIError to be wrapped, got %v", err)
+			}
+			if !strings.Contains(err.Error(), `"key:secret"`) || !strings.Contains(err.Error(), "EnvironmentOTE") {
+				t.Errorf("Expected the error to point to the likely causes, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	version := 1
+	var ifMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"v` + strconv.Itoa(version) + `"`
+		if r.Method == http.MethodGet {
+			w.Header().Set("ETag", etag)
+			w.Write([]byte(`[]`))
+			return
+		}
+
+		ifMatch = append(ifMatch, r.Header.Get("If-Match"))
+		if match := r.Header.Get("If-Match"); match != "" && match != etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`{"code":"PRECONDITION_FAILED","message":"Zone was modified"}`))
+			return
+		}
+		version++
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	records := []libdns.Record{libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")}}
+
+	t.Run("Disabled", func(t *testing.T) {
+		ifMatch = nil
+		provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+		if _, err := provider.GetRecords(ctx, "example.com."); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := provider.SetRecords(ctx, "example.com.", records); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(ifMatch) != 1 || ifMatch[0] != "" {
+			t.Errorf("Expected no If-Match header, got %v", ifMatch)
+		}
+	})
+
+	t.Run("Unchanged zone", func(t *testing.T) {
+		ifMatch = nil
+		provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, EnableOptimisticConcurrency: true}
+		if _, err := provider.GetRecords(ctx, "example.com."); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := `"v` + strconv.Itoa(version) + `"`
+		if _, err := provider.SetRecords(ctx, "example.com.", records); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(ifMatch) != 1 || ifMatch[0] != expected {
+			t.Errorf("Expected If-Match %s, got %v", expected, ifMatch)
+		}
+	})
+
+	t.Run("Several groups", func(t *testing.T) {
+		ifMatch = nil
+		provider := Provider{
+			APIToken:                    "test:secret",
+			APIEndpoint:                 server.URL,
+			EnableOptimisticConcurrency: true,
+			MaxConcurrency:              3,
+		}
+		first := version
+		if _, err := provider.SetRecords(ctx, "example.com.", []libdns.Record{
+			libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+			libdns.Address{Name: "api", IP: netip.MustParseAddr("192.0.2.2")},
+			libdns.TXT{Name: "www", Text: "hello"},
+		}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// Every write is sent with the ETag of the zone left by the previous one
+		var expected []string
+		for v := first; v < first+3; v++ {
+			expected = append(expected, `"v`+strconv.Itoa(v)+`"`)
+		}
+		if !slices.Equal(ifMatch, expected) {
+			t.Errorf("If-Match mismatch: expected %v, got %v", expected, ifMatch)
+		}
+	})
+
+	t.Run("Zone changed concurrently", func(t *testing.T) {
+		provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, EnableOptimisticConcurrency: true}
+		if _, err := provider.GetRecords(ctx, "example.com."); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		version++
+
+		_, err := provider.SetRecords(ctx, "example.com.", records)
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+	})
+}
+
+func TestOptimisticConcurrencyPerDomain(t *testing.T) {
+	// Each write waits for the one to the other domain, which it only gets
+	// if the writes to different domains are not serialized
+	var mu sync.Mutex
+	arrived := make(chan struct{})
+	writes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`[]`))
+			return
+		}
+
+		mu.Lock()
+		writes++
+		if writes == 2 {
+			close(arrived)
+		}
+		mu.Unlock()
+		select {
+		case <-arrived:
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, EnableOptimisticConcurrency: true, MaxRetries: -1}
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, zone := range []string{"example.com.", "example.org."} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = provider.SetRecords(context.Background(), zone, []libdns.Record{
+				libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+			})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("Expected the writes to different domains to run concurrently, got %v", err)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 module github.com/r6c/godaddy
 
-go 1.24
+go 1.24.0
 
-require github.com/libdns/libdns v1.1.0
+require (
+	github.com/libdns/libdns v1.1.0
+	golang.org/x/net v0.50.0
+	golang.org/x/time v0.14.0
+)
+
+require golang.org/x/text v0.34.0 // indirect
@@ -0,0 +1,291 @@
+// Package godaddytest provides a fake GoDaddy API server for testing code
+// built on the godaddy provider without sending requests to GoDaddy.
+//
+// The server implements the subset of the GoDaddy Domains API the provider
+// uses, backed by in-memory zones. Point Provider.APIEndpoint at its URL:
+//
+//	server := godaddytest.NewServer()
+//	defer server.Close()
+//	server.AddDomain("example.com")
+//
+//	provider := godaddy.Provider{APIToken: "key:secret", APIEndpoint: server.URL}
+package godaddytest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Record is a DNS record as stored by GoDaddy, with a name relative to the
+// domain ("@" for the apex) and the TTL in seconds
+type Record struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Data string `json:"data"`
+	TTL  int    `json:"ttl"`
+
+	// SRV-specific fields, GoDaddy stores them separately from Data
+	Priority int    `json:"priority,omitempty"`
+	Weight   int    `json:"weight,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Service  string `json:"service,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// Server is a fake GoDaddy API server. It serves:
+//
+//   - GET /v1/domains, listing the domains, filtered by status
+//   - GET /v1/domains/{domain}
+//   - GET /v1/domains/{domain}/records[/{type}[/{name}]], with offset and limit
+//   - PATCH /v1/domains/{domain}/records, appending records
+//   - PUT /v1/domains/{domain}/records, replacing all records
+//   - PUT /v1/domains/{domain}/records/{type}/{name}, replacing a type and name
+//   - DELETE /v1/domains/{domain}/records/{type}/{name}
+//
+// Requests for unknown domains fail with 404, as they do on GoDaddy.
+type Server struct {
+	*httptest.Server
+
+	// Credentials, if set, is the "key:secret" pair every request must be
+	// authorized with; other requests fail with 401
+	Credentials string
+
+	mu      sync.Mutex
+	domains map[string]*domain
+	order   []string
+}
+
+// domain is a domain hosted by the server along with its records
+type domain struct {
+	status  string
+	records []Record
+}
+
+// NewServer starts and returns a new fake GoDaddy API server without any
+// domains. The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{domains: make(map[string]*domain)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddDomain adds an active domain with the given records, replacing the
+// records if the domain already exists
+func (s *Server) AddDomain(name string, records ...Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = strings.ToLower(name)
+	if _, ok := s.domains[name]; !ok {
+		s.order = append(s.order, name)
+	}
+	s.domains[name] = &domain{status: "ACTIVE", records: slices.Clone(records)}
+}
+
+// Records returns a copy of the records of a domain, or nil if the domain
+// does not exist
+func (s *Server) Records(name string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.domains[strings.ToLower(name)]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(d.records)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Credentials != "" && r.Header.Get("Authorization") != "sso-key "+s.Credentials {
+		writeError(w, http.StatusUnauthorized, "UNABLE_TO_AUTHENTICATE", "Unauthorized : Could not authenticate API key/secret")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/domains")
+	if path == r.URL.Path {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource was not found")
+		return
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if parts[0] == "" {
+		s.listDomains(w, r)
+		return
+	}
+
+	d, ok := s.domains[strings.ToLower(parts[0])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "UNKNOWN_DOMAIN", "The given domain is not registered, or does not have a zone file")
+		return
+	}
+	if len(parts) == 1 {
+		writeJSON(w, map[string]any{
+			"domain":      parts[0],
+			"status":      d.status,
+			"nameServers": []string{"ns01.domaincontrol.com", "ns02.domaincontrol.com"},
+		})
+		return
+	}
+	if parts[1] != "records" || len(parts) > 4 {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource was not found")
+		return
+	}
+
+	var recType, name string
+	if len(parts) > 2 {
+		recType = parts[2]
+	}
+	if len(parts) > 3 {
+		name = parts[3]
+	}
+	matches := func(record Record) bool {
+		return (recType == "" || strings.EqualFold(record.Type, recType)) &&
+			(name == "" || strings.EqualFold(record.Name, name))
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listRecords(w, r, d, matches)
+	case http.MethodPatch:
+		if recType != "" {
+			writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Records can only be appended to the whole domain")
+			return
+		}
+		records, ok := readRecords(w, r)
+		if !ok {
+			return
+		}
+		d.records = append(d.records, records...)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPut:
+		if recType != "" && name == "" {
+			writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Records can only be replaced by type and name")
+			return
+		}
+		records, ok := readRecords(w, r)
+		if !ok {
+			return
+		}
+		kept := slices.DeleteFunc(d.records, matches)
+		for _, record := range records {
+			if recType != "" {
+				record.Type = recType
+				record.Name = name
+			}
+			kept = append(kept, record)
+		}
+		d.records = kept
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		if name == "" {
+			writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Records can only be deleted by type and name")
+			return
+		}
+		if !slices.ContainsFunc(d.records, matches) {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "No records of the given type and name")
+			return
+		}
+		d.records = slices.DeleteFunc(d.records, matches)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Method not allowed")
+	}
+}
+
+// listDomains serves GET /v1/domains, paginated by marker and limit
+func (s *Server) listDomains(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	statuses := query.Get("statuses")
+	marker := strings.ToLower(query.Get("marker"))
+
+	result := []map[string]string{}
+	for _, name := range s.order {
+		if marker != "" {
+			if name == marker {
+				marker = ""
+			}
+			continue
+		}
+		d := s.domains[name]
+		if statuses != "" && !slices.Contains(strings.Split(statuses, ","), d.status) {
+			continue
+		}
+		result = append(result, map[string]string{"domain": name, "status": d.status})
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit >= 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	writeJSON(w, result)
+}
+
+// listRecords serves the records of a domain matching the path, paginated by
+// offset and limit
+func listRecords(w http.ResponseWriter, r *http.Request, d *domain, matches func(Record) bool) {
+	result := []Record{}
+	for _, record := range d.records {
+		if matches(record) {
+			result = append(result, record)
+		}
+	}
+
+	query := r.URL.Query()
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		result = result[min(offset, len(result)):]
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit >= 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	writeJSON(w, result)
+}
+
+// readRecords decodes the records in the body of a request, writing a 422
+// response if they are malformed
+func readRecords(w http.ResponseWriter, r *http.Request) ([]Record, bool) {
+	var records []Record
+	if err := json.NewDecoder(r.Body).Decode(&records); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "INVALID_BODY", "Request body doesn't fulfill schema: "+err.Error())
+		return nil, false
+	}
+	for i, record := range records {
+		if record.Data == "" {
+			writeFieldError(w, i, "data", "is required")
+			return nil, false
+		}
+	}
+	return records, true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format of the GoDaddy API
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"code": code, "message": message})
+}
+
+// writeFieldError writes a validation error for a field of the record at index
+func writeFieldError(w http.ResponseWriter, index int, field, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]any{
+		"code":    "INVALID_BODY",
+		"message": "Request body doesn't fulfill schema, see details in `fields`",
+		"fields": []map[string]string{{
+			"path":    "records." + strconv.Itoa(index) + "." + field,
+			"code":    "MISSING",
+			"message": message,
+		}},
+	})
+}
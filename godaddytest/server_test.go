@@ -0,0 +1,88 @@
+package godaddytest_test
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/r6c/godaddy"
+	"github.com/r6c/godaddy/godaddytest"
+)
+
+func TestServer(t *testing.T) {
+	server := godaddytest.NewServer()
+	defer server.Close()
+	server.Credentials = "key:secret"
+	server.AddDomain("example.com", godaddytest.Record{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 3600})
+
+	provider := godaddy.Provider{APIToken: "key:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	zones, err := provider.ListZones(ctx)
+	if err != nil {
+		t.Fatalf("ListZones failed: %v", err)
+	}
+	if len(zones) != 1 || zones[0].Name != "example.com." {
+		t.Errorf("Zones mismatch: expected [example.com.], got %v", zones)
+	}
+
+	_, err = provider.AppendRecords(ctx, "example.com.", []libdns.Record{
+		libdns.Address{Name: "api", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.2")},
+	})
+	if err != nil {
+		t.Fatalf("AppendRecords failed: %v", err)
+	}
+
+	_, err = provider.SetRecords(ctx, "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.3")},
+	})
+	if err != nil {
+		t.Fatalf("SetRecords failed: %v", err)
+	}
+
+	records, err := provider.GetRecords(ctx, "example.com.")
+	if err != nil {
+		t.Fatalf("GetRecords failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Record count mismatch: expected 2, got %d", len(records))
+	}
+
+	_, err = provider.DeleteRecords(ctx, "example.com.", []libdns.Record{
+		libdns.Address{Name: "api", IP: netip.MustParseAddr("192.0.2.2")},
+	})
+	if err != nil {
+		t.Fatalf("DeleteRecords failed: %v", err)
+	}
+
+	expected := []godaddytest.Record{{Type: "A", Name: "www", Data: "192.0.2.3", TTL: 3600}}
+	stored := server.Records("example.com")
+	if len(stored) != 1 || stored[0] != expected[0] {
+		t.Errorf("Stored records mismatch: expected %v, got %v", expected, stored)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	server := godaddytest.NewServer()
+	defer server.Close()
+	server.Credentials = "key:secret"
+	server.AddDomain("example.com")
+
+	ctx := context.Background()
+
+	provider := godaddy.Provider{APIToken: "key:secret", APIEndpoint: server.URL}
+	_, err := provider.GetRecords(ctx, "unknown.com.")
+	if !errors.Is(err, godaddy.ErrZoneNotFound) {
+		t.Errorf("Expected ErrZoneNotFound for an unknown domain, got %v", err)
+	}
+
+	provider = godaddy.Provider{APIToken: "key:wrong", APIEndpoint: server.URL}
+	var apiErr *godaddy.APIError
+	_, err = provider.GetRecords(ctx, "example.com.")
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 401 {
+		t.Errorf("Expected a 401 APIError for wrong credentials, got %v", err)
+	}
+}
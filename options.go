@@ -0,0 +1,182 @@
+package godaddy
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Provider created with NewProvider
+type Option func(*Provider)
+
+// NewProvider returns a Provider configured with the given options. Unlike
+// a Provider created as a struct literal, whose configuration is only checked
+// when the first request is sent, it fails right away if no credentials are
+// set or the configuration is invalid.
+func NewProvider(opts ...Option) (*Provider, error) {
+	p := &Provider{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := p.validateCredentials(); err != nil {
+		return nil, err
+	}
+	if err := p.validateConfig(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Clone returns a copy of the provider with the given options applied, e.g. to
+// manage some zones with a different timeout or concurrency. The original
+// provider is not modified.
+//
+// The copy shares the HTTPClient, Logger and hooks of the original, and when
+// none is set, the connection pool of the default transport, but not its
+// state: it starts with empty caches and no known ETags or rate limit
+// status. If the copy keeps the RateLimit and RateLimitInterval of the
+// original, both share a single rate limiter, so that together they stay
+// within the limit; otherwise the copy is throttled on its own. The maps of
+// the original, such as ExtraHeaders, are copied, so changing them in the
+// copy does not affect the original. Like a Provider created as a struct
+// literal, the copy's configuration is only checked when the first request is
+// sent.
+func (p *Provider) Clone(opts ...Option) *Provider {
+	clone := *p
+	clone.state = nil
+	clone.ExtraHeaders = maps.Clone(p.ExtraHeaders)
+	clone.TypeTTLDefaults = maps.Clone(p.TypeTTLDefaults)
+	clone.RecordLimits = maps.Clone(p.RecordLimits)
+	if p.EnforceMinTTL != nil {
+		enforce := *p.EnforceMinTTL
+		clone.EnforceMinTTL = &enforce
+	}
+
+	for _, opt := range opts {
+		opt(&clone)
+	}
+
+	if limiter := p.getLimiter(); limiter != nil &&
+		clone.RateLimit == p.RateLimit && clone.RateLimitInterval == p.RateLimitInterval {
+		clone.state = &providerState{limiter: limiter}
+	}
+	return &clone
+}
+
+// WithAPIToken sets the combined GoDaddy SSO key in the form "key:secret"
+func WithAPIToken(token string) Option {
+	return func(p *Provider) {
+		p.APIToken = token
+	}
+}
+
+// WithAPIKey sets the two halves of a GoDaddy SSO key
+func WithAPIKey(key, secret string) Option {
+	return func(p *Provider) {
+		p.APIKey = key
+		p.APISecret = secret
+	}
+}
+
+// WithShopperID sets the X-Shopper-Id sent on every request
+func WithShopperID(shopperID string) Option {
+	return func(p *Provider) {
+		p.ShopperID = shopperID
+	}
+}
+
+// WithExtraHeaders sets headers sent on every request in addition to the
+// provider's own
+func WithExtraHeaders(headers map[string]string) Option {
+	return func(p *Provider) {
+		p.ExtraHeaders = headers
+	}
+}
+
+// WithUserAgent sets the User-Agent sent on every request
+func WithUserAgent(userAgent string) Option {
+	return func(p *Provider) {
+		p.UserAgent = userAgent
+	}
+}
+
+// WithEnvironment selects the GoDaddy environment, EnvironmentProduction or
+// EnvironmentOTE
+func WithEnvironment(environment string) Option {
+	return func(p *Provider) {
+		p.Environment = environment
+	}
+}
+
+// WithAPIEndpoint overrides the GoDaddy API base URL
+func WithAPIEndpoint(endpoint string) Option {
+	return func(p *Provider) {
+		p.APIEndpoint = endpoint
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.HTTPClient = client
+	}
+}
+
+// WithHTTPTimeout sets the timeout for HTTP requests
+func WithHTTPTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		p.HTTPTimeout = timeout
+	}
+}
+
+// WithProxy sets the URL of the proxy requests are sent through
+func WithProxy(proxy string) Option {
+	return func(p *Provider) {
+		p.Proxy = proxy
+	}
+}
+
+// WithLogger sets the logger receiving debug logs of every request
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Provider) {
+		p.Logger = logger
+	}
+}
+
+// WithMaxRetries sets how many times failed requests are retried
+func WithMaxRetries(maxRetries int) Option {
+	return func(p *Provider) {
+		p.MaxRetries = maxRetries
+	}
+}
+
+// WithMaxConcurrency sets how many requests are sent at the same time
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(p *Provider) {
+		p.MaxConcurrency = maxConcurrency
+	}
+}
+
+// validateCredentials checks that either APIKey and APISecret, or an APIToken
+// of the form "key:secret", are set
+func (p *Provider) validateCredentials() error {
+	if p.APIKey != "" || p.APISecret != "" {
+		if p.APIKey == "" || p.APISecret == "" {
+			return errors.New("both APIKey and APISecret must be set")
+		}
+		return nil
+	}
+
+	if p.APIToken == "" {
+		return errors.New("missing GoDaddy credentials: set APIToken or APIKey and APISecret")
+	}
+	key, secret, ok := strings.Cut(p.APIToken, ":")
+	if !ok || key == "" || secret == "" {
+		return errors.New(`invalid APIToken: expected the form "key:secret"`)
+	}
+	return nil
+}
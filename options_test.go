@@ -0,0 +1,125 @@
+package godaddy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		expectError bool
+	}{
+		{"API token", []Option{WithAPIToken("key:secret")}, false},
+		{"API key and secret", []Option{WithAPIKey("key", "secret")}, false},
+		{"Key and secret take precedence", []Option{WithAPIToken("invalid"), WithAPIKey("key", "secret")}, false},
+		{"No credentials", nil, true},
+		{"Empty API token", []Option{WithAPIToken("")}, true},
+		{"API token without secret", []Option{WithAPIToken("key")}, true},
+		{"API token with empty half", []Option{WithAPIToken("key:")}, true},
+		{"API key without secret", []Option{WithAPIKey("key", "")}, true},
+		{"API secret without key", []Option{WithAPIKey("", "secret")}, true},
+		{"Unknown environment", []Option{WithAPIToken("key:secret"), WithEnvironment("staging")}, true},
+		{"Invalid proxy", []Option{WithAPIToken("key:secret"), WithProxy("proxy:3128")}, true},
+		{"MinTTL above MaxTTL", []Option{WithAPIToken("key:secret"), func(p *Provider) { p.MinTTL, p.MaxTTL = 2*time.Hour, time.Hour }}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(tt.opts...)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got provider %+v", provider)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if provider.getCredentials() != "key:secret" {
+				t.Errorf("Credentials mismatch: expected key:secret, got %s", provider.getCredentials())
+			}
+		})
+	}
+}
+
+func TestNewProviderOptions(t *testing.T) {
+	provider, err := NewProvider(
+		WithAPIToken("key:secret"),
+		WithShopperID("12345"),
+		WithUserAgent("mytool/1.0"),
+		WithEnvironment(EnvironmentOTE),
+		WithHTTPTimeout(10*time.Second),
+		WithMaxRetries(5),
+		WithMaxConcurrency(4),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if provider.ShopperID != "12345" {
+		t.Errorf("ShopperID mismatch: expected 12345, got %s", provider.ShopperID)
+	}
+	if provider.getUserAgent() != "mytool/1.0" {
+		t.Errorf("UserAgent mismatch: expected mytool/1.0, got %s", provider.getUserAgent())
+	}
+	if host := provider.getApiHost(); host != "https://api.ote-godaddy.com" {
+		t.Errorf("API host mismatch: expected https://api.ote-godaddy.com, got %s", host)
+	}
+	if provider.HTTPTimeout != 10*time.Second {
+		t.Errorf("HTTPTimeout mismatch: expected 10s, got %s", provider.HTTPTimeout)
+	}
+	if provider.getMaxRetries() != 5 {
+		t.Errorf("MaxRetries mismatch: expected 5, got %d", provider.getMaxRetries())
+	}
+	if provider.MaxConcurrency != 4 {
+		t.Errorf("MaxConcurrency mismatch: expected 4, got %d", provider.MaxConcurrency)
+	}
+}
+
+func TestClone(t *testing.T) {
+	original, err := NewProvider(
+		WithAPIToken("key:secret"),
+		WithHTTPTimeout(10*time.Second),
+		WithMaxConcurrency(2),
+		WithExtraHeaders(map[string]string{"X-Gateway-Key": "original"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	original.RateLimit = 60
+	original.CacheTTL = time.Minute
+	original.getState().cacheRecords("example.com", []godaddyRecord{{Type: "A", Name: "www", Data: "192.0.2.1"}}, time.Now().Add(time.Minute))
+
+	clone := original.Clone(WithHTTPTimeout(time.Minute), WithMaxConcurrency(8))
+	clone.ExtraHeaders["X-Gateway-Key"] = "clone"
+
+	if original.HTTPTimeout != 10*time.Second || original.MaxConcurrency != 2 {
+		t.Errorf("Original modified: HTTPTimeout %s, MaxConcurrency %d", original.HTTPTimeout, original.MaxConcurrency)
+	}
+	if original.ExtraHeaders["X-Gateway-Key"] != "original" {
+		t.Errorf("Original ExtraHeaders modified: %v", original.ExtraHeaders)
+	}
+	if clone.HTTPTimeout != time.Minute || clone.MaxConcurrency != 8 {
+		t.Errorf("Options not applied: HTTPTimeout %s, MaxConcurrency %d", clone.HTTPTimeout, clone.MaxConcurrency)
+	}
+	if clone.getCredentials() != "key:secret" || clone.CacheTTL != time.Minute {
+		t.Errorf("Fields not copied: credentials %s, CacheTTL %s", clone.getCredentials(), clone.CacheTTL)
+	}
+
+	if _, ok := clone.getState().cachedRecords("example.com", time.Now()); ok {
+		t.Error("Expected the clone to start with an empty cache")
+	}
+	if _, ok := original.getState().cachedRecords("example.com", time.Now()); !ok {
+		t.Error("Expected the original to keep its cache")
+	}
+	if clone.getLimiter() != original.getLimiter() {
+		t.Error("Expected the clone to share the rate limiter of the original")
+	}
+
+	throttled := original.Clone(func(p *Provider) { p.RateLimit = 30 })
+	if throttled.getLimiter() == original.getLimiter() {
+		t.Error("Expected a clone with another rate limit to have its own rate limiter")
+	}
+}
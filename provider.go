@@ -3,67 +3,454 @@
 package godaddy
 
 import (
-	"bytes"
+	"cmp"
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
+	"maps"
+	"math"
 	"net/http"
 	"net/netip"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/libdns/libdns"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
 )
 
 // Provider implements libdns interfaces for GoDaddy DNS
 type Provider struct {
+	// APIToken is the combined GoDaddy SSO key in the form "key:secret".
+	// It is only used when APIKey and APISecret are not both set.
 	APIToken string `json:"api_token,omitempty"`
 
+	// APIKey and APISecret are the two halves of a GoDaddy SSO key. When both
+	// are set, they take precedence over APIToken.
+	APIKey    string `json:"api_key,omitempty"`
+	APISecret string `json:"api_secret,omitempty"`
+
+	// ShopperID is sent as the X-Shopper-Id header on every request when set,
+	// allowing resellers to manage domains on behalf of a customer account.
+	ShopperID string `json:"shopper_id,omitempty"`
+
+	// ExtraHeaders are sent on every request, e.g. for a corporate gateway or
+	// proxy in front of GoDaddy that requires headers of its own. They cannot
+	// replace the headers set by the provider, such as Authorization, Accept,
+	// User-Agent and X-Shopper-Id, nor the headers added to a request with
+	// ContextWithHeaders.
+	ExtraHeaders map[string]string `json:"extra_headers,omitempty"`
+
+	// UserAgent replaces the default User-Agent header ("libdns-godaddy/1.0")
+	// to identify the tool embedding the provider. To keep identifying this
+	// library as well, append DefaultUserAgent, e.g.
+	// "mytool/2.0 " + godaddy.DefaultUserAgent.
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// Environment selects the GoDaddy environment: EnvironmentProduction
+	// ("production", https://api.godaddy.com) or EnvironmentOTE ("ote",
+	// https://api.ote-godaddy.com). When set, it takes precedence over UseOTE;
+	// requests fail with an error for any other value.
+	Environment string `json:"environment,omitempty"`
+
 	// UseOTE enables the use of GoDaddy's OTE (Operational Test Environment)
 	// instead of the production environment. This is useful for development and testing.
 	// When true, uses https://api.ote-godaddy.com
 	// When false (default), uses https://api.godaddy.com
+	//
+	// Deprecated: Set Environment to EnvironmentOTE instead.
 	UseOTE bool `json:"use_ote,omitempty"`
 
+	// APIEndpoint overrides the GoDaddy API base URL, e.g. to point the provider
+	// at a mock server or a proxy. When set, it takes precedence over
+	// Environment and UseOTE.
+	APIEndpoint string `json:"api_endpoint,omitempty"`
+
 	// HTTPTimeout specifies the timeout for HTTP requests.
 	// If zero, a default timeout of 30 seconds is used.
 	HTTPTimeout time.Duration `json:"http_timeout,omitempty"`
+
+	// PerRequestTimeout caps every single request sent to GoDaddy, each retry
+	// getting its own timeout, by deriving its context with context.WithTimeout.
+	// It allows a generous deadline on the context of a bulk operation while
+	// still bounding every request; a shorter deadline of that context wins.
+	// If zero, requests are only bounded by HTTPTimeout and the context.
+	PerRequestTimeout time.Duration `json:"per_request_timeout,omitempty"`
+
+	// MaxTTL specifies the largest TTL accepted for records written to GoDaddy.
+	// If zero, GoDaddy's maximum of 604800 seconds (one week) is used.
+	MaxTTL time.Duration `json:"max_ttl,omitempty"`
+
+	// ClampMaxTTL lowers TTLs above MaxTTL to MaxTTL instead of rejecting
+	// the record with an error.
+	ClampMaxTTL bool `json:"clamp_max_ttl,omitempty"`
+
+	// MinTTL is the lowest TTL records are written with, see EnforceMinTTL.
+	// If zero, GoDaddy's documented minimum of 600 seconds is used. Raise it
+	// to enforce a stricter policy, or lower it if GoDaddy relaxes its limit.
+	MinTTL time.Duration `json:"min_ttl,omitempty"`
+
+	// EnforceMinTTL raises TTLs below MinTTL to MinTTL. If nil, it defaults
+	// to true. Set it to false to send non-zero TTLs unchanged and let GoDaddy
+	// reject the ones it does not allow; records without a TTL still get
+	// DefaultTTL, or MinTTL.
+	EnforceMinTTL *bool `json:"enforce_min_ttl,omitempty"`
+
+	// DefaultTTL is used for records written without a TTL. It is still
+	// raised to MinTTL unless EnforceMinTTL is false. If zero, records without
+	// a TTL get MinTTL.
+	DefaultTTL time.Duration `json:"default_ttl,omitempty"`
+
+	// TypeTTLDefaults overrides DefaultTTL for records of the given types
+	// written without a TTL, e.g. a short TTL for TXT records used by ACME
+	// challenges and a long one for NS records. Types are matched regardless
+	// of case. A record without a TTL gets the TTL of its type, or DefaultTTL,
+	// or MinTTL, in that order, which is then raised to MinTTL unless
+	// EnforceMinTTL is false.
+	TypeTTLDefaults map[string]time.Duration `json:"type_ttl_defaults,omitempty"`
+
+	// HTTPClient is an optional client used for all requests, e.g. to share a
+	// custom transport, proxy or connection pool across providers. If its
+	// Timeout is zero, HTTPTimeout (or its default) is applied to a copy of it.
+	HTTPClient *http.Client `json:"-"`
+
+	// Proxy is the URL of an HTTP, HTTPS or SOCKS5 proxy all requests are sent
+	// through, e.g. "http://proxy.internal:3128". It is ignored when
+	// HTTPClient is set, as the injected client's transport is used as is.
+	Proxy string `json:"proxy,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the TLS certificate of
+	// APIEndpoint, for mock or staging endpoints with self-signed certificates.
+	//
+	// WARNING: this exposes the credentials and all requests to anyone able to
+	// intercept the connection. Never enable it outside of testing. It is
+	// ignored unless APIEndpoint is set to a host other than GoDaddy's, so it
+	// never applies to the production and OTE APIs, and it is ignored when
+	// HTTPClient is set.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+
+	// Logger receives debug logs for every request sent to GoDaddy, including
+	// the method, URL, status code and retry attempts. The Authorization header
+	// is redacted. If nil, nothing is logged.
+	Logger *slog.Logger `json:"-"`
+
+	// OnRequest, if set, is called with every request right before it is sent
+	// to GoDaddy, including retries, e.g. to count calls per endpoint.
+	OnRequest func(req *http.Request) `json:"-"`
+
+	// OnResponse, if set, is called after every request sent to GoDaddy with
+	// the response and the time it took, e.g. to record latency histograms.
+	// The response body has already been read and closed. If the request
+	// failed without a response, resp is nil and err is set.
+	OnResponse func(req *http.Request, resp *http.Response, elapsed time.Duration, err error) `json:"-"`
+
+	// DryRun prevents any change to the zone. AppendRecords, SetRecords,
+	// DeleteRecords and the other mutating methods still convert and validate
+	// the records and read the zone where needed, but instead of sending the
+	// modifying requests they log them (at info level, if a Logger is set) and
+	// return the records as if the requests had succeeded.
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// MaxRetries specifies how many times a request is retried when GoDaddy
+	// responds with HTTP 429 (Too Many Requests) or, for idempotent requests,
+	// with a server error (HTTP 5xx).
+	// If zero, a default of 3 retries is used. If negative, requests are not retried.
+	MaxRetries int `json:"max_retries,omitempty"`
+
+	// ProvisioningRetryWindow keeps retrying modifying requests GoDaddy
+	// rejects with HTTP 409 or 422 without naming any invalid field, as it
+	// does while the DNS of a recently transferred domain is being
+	// provisioned, for up to this long after the first such response.
+	// Validation errors, which name the rejected fields, are never retried.
+	// If zero, these responses are not retried.
+	ProvisioningRetryWindow time.Duration `json:"provisioning_retry_window,omitempty"`
+
+	// RetryBaseDelay is the delay before the first retry when GoDaddy sends no
+	// Retry-After header. It doubles with every further retry, up to
+	// RetryMaxDelay. If zero, a default of 1 second is used.
+	RetryBaseDelay time.Duration `json:"retry_base_delay,omitempty"`
+
+	// RetryMaxDelay caps the delay between two attempts. If zero, a default of
+	// 30 seconds is used.
+	RetryMaxDelay time.Duration `json:"retry_max_delay,omitempty"`
+
+	// DisableRetryJitter waits exactly the backoff delay before retrying. By
+	// default a random delay between zero and the backoff delay is used
+	// ("full jitter"), so that requests rate limited at the same time are not
+	// all retried at the same time.
+	DisableRetryJitter bool `json:"disable_retry_jitter,omitempty"`
+
+	// PageSize specifies how many items are requested per page when listing
+	// the records of a zone or the domains of the account. If zero, a default
+	// of 500 is used.
+	PageSize int `json:"page_size,omitempty"`
+
+	// MaxConcurrency specifies how many requests are sent at the same time when
+	// an operation needs one request per record or per type and name, as in
+	// SetRecords and DeleteRecords. If zero or one, requests are sent one after
+	// the other. The returned records are in the same order either way.
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+
+	// RateLimit throttles requests to at most this many per RateLimitInterval,
+	// spread evenly, to stay below GoDaddy's rate limits (60 requests per
+	// minute) instead of relying on retries. The limit is shared by all
+	// requests of the provider, including concurrent ones and retries; waiting
+	// stops when the context is done. If zero, requests are not throttled.
+	RateLimit int `json:"rate_limit,omitempty"`
+
+	// RateLimitInterval is the interval RateLimit applies to.
+	// If zero, a default of one minute is used.
+	RateLimitInterval time.Duration `json:"rate_limit_interval,omitempty"`
+
+	// EnableOptimisticConcurrency protects against overwriting concurrent
+	// changes. The ETag GoDaddy returns when the whole zone is listed (e.g. by
+	// GetRecords) is sent as If-Match on the next modifying request to that
+	// zone, which then fails with ErrConflict if the zone changed in between.
+	// Requests are sent without If-Match when no ETag is known. Operations
+	// writing several types and names, such as SetRecords, send their requests
+	// to a zone one at a time regardless of MaxConcurrency, each with the ETag
+	// left by the previous one; if GoDaddy returns none on a write, the zone is
+	// listed again to get it. That listing takes in any change made by someone
+	// else since the write, which is then not detected.
+	EnableOptimisticConcurrency bool `json:"enable_optimistic_concurrency,omitempty"`
+
+	// CacheTTL enables an in-memory cache of the records of each zone, so that
+	// GetRecords, DeleteRecords and ComputeDiff calls within CacheTTL of each
+	// other list the zone only once. Any modifying request to a zone drops its
+	// cached records. SetRecords always lists the zone afresh, as it decides
+	// which records to write from it, and so does DeleteRecords before
+	// replacing the whole zone, see BulkDeleteThreshold. If zero, records are
+	// always fetched from GoDaddy.
+	CacheTTL time.Duration `json:"cache_ttl,omitempty"`
+
+	// ExcludeSystemRecords leaves the records GoDaddy creates and manages
+	// itself out of the results of GetRecords, GetRecordsByTypeName,
+	// GetRecordsByName and ComputeDiff, so that reconciling a zone does not
+	// try to delete them. See isSystemRecord for the records excluded.
+	ExcludeSystemRecords bool `json:"exclude_system_records,omitempty"`
+
+	// RecordLimits is the largest number of records of each type, e.g. "TXT",
+	// the zone may hold. When set, AppendRecords, SetRecords and
+	// ReplaceAllRecords count the records the zone would hold after the
+	// write and fail with ErrRecordLimit before sending any modifying
+	// request if that exceeds a limit, rather than having GoDaddy reject the
+	// batch midway. AppendRecords lists the zone to count its records. Only
+	// the records within the zone are counted, not those of its registered
+	// domain outside of it. As GoDaddy may change its limits, none are
+	// applied by default.
+	RecordLimits map[string]int `json:"record_limits,omitempty"`
+
+	// SortRecords orders the records returned by GetRecords,
+	// GetRecordsByTypeName and GetRecordsByName by name, type and data, for
+	// reproducible output in diffs and golden-file tests. Otherwise they are
+	// returned in the order GoDaddy lists them, which is not stable.
+	SortRecords bool `json:"sort_records,omitempty"`
+
+	// AbsoluteNames makes GetRecords, GetRecordsByTypeName and
+	// GetRecordsByName return records with fully qualified names, e.g.
+	// "www.example.com." instead of "www" and "example.com." instead of "@".
+	// Names are relative to the zone by default, as libdns expects. Either
+	// form is accepted when writing records.
+	AbsoluteNames bool `json:"absolute_names,omitempty"`
+
+	// EnableConditionalGet remembers the ETag and records of every page GoDaddy
+	// returns when listing records, and sends the ETag as If-None-Match when
+	// the same page is listed again. If GoDaddy responds 304 Not Modified, the
+	// remembered records are used instead of transferring and parsing them
+	// again. Pages listed without an ETag are always fetched normally.
+	EnableConditionalGet bool `json:"enable_conditional_get,omitempty"`
+
+	// BulkDeleteThreshold makes DeleteRecords rewrite the whole zone with a
+	// single PUT, instead of sending one request per type and name, when more
+	// than this many types and names are affected. The records that remain are
+	// computed from the zone as listed by DeleteRecords (or as cached, see
+	// CacheTTL), so records created in between are lost. If zero, records are
+	// always deleted per type and name.
+	BulkDeleteThreshold int `json:"bulk_delete_threshold,omitempty"`
+
+	// now and sleep replace time.Now and sleepContext when set, so that tests
+	// can control time without waiting for real backoff delays
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+
+	// state is shared by all the requests of the provider, see getState
+	state *providerState
 }
 
+// getDomain returns the registered domain hosting the zone. GoDaddy only hosts
+// registered domains, so for a zone delegated below one, such as
+// k8s.example.com, this is the parent domain example.com.
 func getDomain(zone string) string {
-	return strings.TrimSuffix(zone, ".")
+	zone = toASCII(strings.TrimSuffix(zone, "."))
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(zone); err == nil {
+		return domain
+	}
+	return zone
 }
 
-func getRecordName(zone, name string) string {
-	if name == "@" {
-		return "@"
+// toASCII converts an internationalized domain name, such as münchen.de, to
+// the punycode form GoDaddy expects, xn--mnchen-3ya.de. Labels that are
+// already ASCII are kept unchanged, including their case and labels such as
+// "_acme-challenge" or "*" that are not valid hostnames; names with invalid
+// internationalized labels are returned unchanged for GoDaddy to reject.
+func toASCII(name string) string {
+	if isASCII(name) {
+		return name
+	}
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+		ascii, err := idna.Lookup.ToASCII(label)
+		if err != nil {
+			return name
+		}
+		labels[i] = ascii
+	}
+	return strings.Join(labels, ".")
+}
+
+// isASCII reports whether s consists of ASCII characters only
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// getZonePrefix returns the labels of the zone below its registered domain,
+// e.g. "k8s" for the zone k8s.example.com, or "" if the zone is the domain
+func getZonePrefix(zone string) string {
+	zone = toASCII(strings.TrimSuffix(zone, "."))
+	domain := getDomain(zone)
+	if len(zone) <= len(domain) {
+		return ""
+	}
+	return zone[:len(zone)-len(domain)-1]
+}
+
+// toDomainName converts a name relative to the zone, as used throughout the
+// provider, into the name relative to the registered domain used by GoDaddy
+func toDomainName(zone, name string) string {
+	prefix := getZonePrefix(zone)
+	switch {
+	case prefix == "":
+		return name
+	case name == "@":
+		return prefix
+	default:
+		return name + "." + prefix
+	}
+}
+
+// fromDomainName converts a name relative to the registered domain, as GoDaddy
+// returns it, into a name relative to the zone. It reports false if the name
+// lies outside of the zone, which only happens for delegated zones.
+func fromDomainName(zone, name string) (string, bool) {
+	prefix := getZonePrefix(zone)
+	suffix := "." + prefix
+	switch {
+	case prefix == "":
+		return name, true
+	case strings.EqualFold(name, prefix):
+		return "@", true
+	case len(name) > len(suffix) && strings.EqualFold(name[len(name)-len(suffix):], suffix):
+		return name[:len(name)-len(suffix)], true
+	}
+	return "", false
+}
+
+// toDomainRecords returns copies of the records named relative to the
+// registered domain, ready to be sent to GoDaddy
+func toDomainRecords(zone string, grs []godaddyRecord) []godaddyRecord {
+	if getZonePrefix(zone) == "" {
+		return grs
+	}
+	records := make([]godaddyRecord, len(grs))
+	for i, gr := range grs {
+		gr.Name = toDomainName(zone, gr.Name)
+		records[i] = gr
 	}
-	return strings.TrimSuffix(strings.TrimSuffix(name, zone), ".")
+	return records
 }
 
-func (p *Provider) getApiHost() string {
-	if p.UseOTE {
-		return "https://api.ote-godaddy.com"
+// fromDomainRecords returns the records returned by GoDaddy that lie within
+// the zone, named relative to the zone
+func fromDomainRecords(zone string, grs []godaddyRecord) []godaddyRecord {
+	var records []godaddyRecord
+	for _, gr := range grs {
+		if name, ok := fromRecordName(zone, gr); ok {
+			gr.Name = name
+			records = append(records, gr)
+		}
 	}
-	return "https://api.godaddy.com"
+	return records
 }
 
-func (p *Provider) getHTTPClient() *http.Client {
-	timeout := p.HTTPTimeout
-	if timeout == 0 {
-		timeout = 30 * time.Second
+// fromRecordName returns the name of a record returned by GoDaddy relative to
+// the zone, reporting false if it lies outside of the zone. The fully qualified
+// name is used when GoDaddy includes it and it lies within the registered
+// domain; otherwise the name relative to the registered domain is converted.
+// The FQDN of an SRV record may include its service and protocol, which
+// GoDaddy returns separately from its name.
+func fromRecordName(zone string, gr godaddyRecord) (string, bool) {
+	fqdn := toASCII(strings.TrimSuffix(gr.FQDN, "."))
+	if gr.Type == "SRV" && gr.Service != "" && gr.Protocol != "" {
+		if prefix := gr.Service + "." + gr.Protocol + "."; len(fqdn) > len(prefix) &&
+			strings.EqualFold(fqdn[:len(prefix)], prefix) {
+			fqdn = fqdn[len(prefix):]
+		}
+	}
+
+	domain := getDomain(zone)
+	if fqdn == "" || (!strings.EqualFold(fqdn, domain) && !hasSuffixFold(fqdn, "."+domain)) {
+		return fromDomainName(zone, gr.Name)
 	}
-	return &http.Client{
-		Timeout: timeout,
+
+	zone = toASCII(strings.TrimSuffix(zone, "."))
+	switch {
+	case strings.EqualFold(fqdn, zone):
+		return "@", true
+	case hasSuffixFold(fqdn, "."+zone):
+		return fqdn[:len(fqdn)-len(zone)-1], true
 	}
+	return "", false
 }
 
-func (p *Provider) setCommonHeaders(req *http.Request) {
-	req.Header.Set("Authorization", "sso-key "+p.APIToken)
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "libdns-godaddy/1.0")
+// hasSuffixFold reports whether s ends with suffix, ignoring case
+func hasSuffixFold(s, suffix string) bool {
+	return len(s) >= len(suffix) && strings.EqualFold(s[len(s)-len(suffix):], suffix)
+}
+
+// getRecordName returns the name relative to the zone, which GoDaddy expects
+// for zones that are registered domains; see toDomainName for delegated zones.
+// The zone apex is always returned as "@". Trailing dots on the zone and the
+// name are ignored, and the zone is only stripped on a label boundary.
+func getRecordName(zone, name string) string {
+	if name == "@" {
+		return "@"
+	}
+	zone = toASCII(strings.TrimSuffix(zone, "."))
+	name = toASCII(strings.TrimSuffix(name, "."))
+
+	if name == "" || strings.EqualFold(name, zone) {
+		return "@"
+	}
+	if suffix := "." + zone; zone != "" && len(name) > len(suffix) &&
+		strings.EqualFold(name[len(name)-len(suffix):], suffix) {
+		return name[:len(name)-len(suffix)]
+	}
+	return name
 }
 
 // godaddyRecord represents a DNS record as returned by GoDaddy API
@@ -72,6 +459,98 @@ type godaddyRecord struct {
 	Name string `json:"name"`
 	Data string `json:"data"`
 	TTL  int    `json:"ttl"`
+
+	// SRV-specific fields, GoDaddy returns them separately from Data
+	Priority int    `json:"priority,omitempty"`
+	Weight   int    `json:"weight,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Service  string `json:"service,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+
+	// FQDN is the fully qualified name of the record, which GoDaddy includes
+	// in some responses. It tells reliably whether a record of the registered
+	// domain lies within a delegated zone, see fromRecordName. It is read-only
+	// and never sent back, see MarshalJSON.
+	FQDN string `json:"fqdn,omitempty"`
+
+	// Extra holds the fields GoDaddy returned that are not known above, so
+	// that records read from the zone and written back keep them. GoDaddy's
+	// record schema currently has no comment or other metadata field, and
+	// libdns records have nowhere to carry one.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// godaddyRecordFields are the JSON fields of godaddyRecord that are not kept
+// in Extra
+var godaddyRecordFields = []string{"type", "name", "data", "ttl", "priority", "weight", "port", "service", "protocol", "fqdn"}
+
+// UnmarshalJSON decodes a record, keeping unknown fields in Extra
+func (gr *godaddyRecord) UnmarshalJSON(data []byte) error {
+	type plain godaddyRecord
+	if err := json.Unmarshal(data, (*plain)(gr)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, field := range godaddyRecordFields {
+		delete(fields, field)
+	}
+	gr.Extra = nil
+	if len(fields) > 0 {
+		gr.Extra = fields
+	}
+	return nil
+}
+
+// MarshalJSON encodes a record along with the unknown fields in Extra. The
+// fields GoDaddy requires for MX and SRV records are always included, even
+// when zero, e.g. an SRV record with priority 0 and weight 0. The read-only
+// FQDN is left out, so records read and written back don't echo it.
+func (gr godaddyRecord) MarshalJSON() ([]byte, error) {
+	type plain godaddyRecord
+	gr.FQDN = ""
+	data, err := json.Marshal(plain(gr))
+
+	// omitempty drops these fields when zero
+	zero := make(map[string]json.RawMessage)
+	switch strings.ToUpper(gr.Type) {
+	case "SRV":
+		if gr.Weight == 0 {
+			zero["weight"] = json.RawMessage("0")
+		}
+		if gr.Port == 0 {
+			zero["port"] = json.RawMessage("0")
+		}
+		if gr.Service == "" {
+			zero["service"] = json.RawMessage(`""`)
+		}
+		if gr.Protocol == "" {
+			zero["protocol"] = json.RawMessage(`""`)
+		}
+		fallthrough
+	case "MX":
+		if gr.Priority == 0 {
+			zero["priority"] = json.RawMessage("0")
+		}
+	}
+	if err != nil || (len(gr.Extra) == 0 && len(zero) == 0) {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range gr.Extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	maps.Copy(fields, zero)
+	return json.Marshal(fields)
 }
 
 // convertToLibdnsRecord converts a GoDaddy API record to a libdns Record
@@ -96,6 +575,8 @@ func convertToLibdnsRecord(gr godaddyRecord) libdns.Record {
 			IP:   ip,
 		}
 	case "TXT":
+		// GoDaddy stores the text unquoted, which is also what libdns expects,
+		// so values with spaces, quotes or semicolons are used as-is
 		return libdns.TXT{
 			Name: gr.Name,
 			TTL:  ttl,
@@ -108,16 +589,26 @@ func convertToLibdnsRecord(gr godaddyRecord) libdns.Record {
 			Target: gr.Data,
 		}
 	case "MX":
-		// MX data format is "priority target" (e.g., "10 mail.example.com")
-		parts := strings.SplitN(gr.Data, " ", 2)
-		var preference uint16
+		// GoDaddy returns the preference in the priority field with the target
+		// alone in Data, but records written as "priority target" (e.g. "10
+		// mail.example.com") keep it embedded in Data. The field wins if both
+		// are set; a zero field cannot be told apart from a missing one, so a
+		// target alone without the field has preference 0.
+		fields := strings.Fields(gr.Data)
+		hasPriority := gr.Priority > 0 && gr.Priority <= math.MaxUint16
+		var preference uint64
 		var target string
-		if len(parts) == 2 {
-			if pref, err := strconv.ParseUint(parts[0], 10, 16); err == nil {
-				preference = uint16(pref)
-				target = parts[1]
-			} else {
-				// If parsing fails, fallback to RR
+		switch {
+		case gr.Priority < 0 || gr.Priority > math.MaxUint16:
+			return libdns.RR{
+				Name: gr.Name,
+				TTL:  ttl,
+				Type: gr.Type,
+				Data: gr.Data,
+			}
+		case len(fields) == 2:
+			pref, err := strconv.ParseUint(fields[0], 10, 16)
+			if err != nil {
 				return libdns.RR{
 					Name: gr.Name,
 					TTL:  ttl,
@@ -125,7 +616,10 @@ func convertToLibdnsRecord(gr godaddyRecord) libdns.Record {
 					Data: gr.Data,
 				}
 			}
-		} else {
+			preference, target = pref, fields[1]
+		case len(fields) == 1:
+			target = fields[0]
+		default:
 			// Invalid format, fallback to RR
 			return libdns.RR{
 				Name: gr.Name,
@@ -134,10 +628,13 @@ func convertToLibdnsRecord(gr godaddyRecord) libdns.Record {
 				Data: gr.Data,
 			}
 		}
+		if hasPriority {
+			preference = uint64(gr.Priority)
+		}
 		return libdns.MX{
 			Name:       gr.Name,
 			TTL:        ttl,
-			Preference: preference,
+			Preference: uint16(preference),
 			Target:     target,
 		}
 	case "NS":
@@ -147,8 +644,86 @@ func convertToLibdnsRecord(gr godaddyRecord) libdns.Record {
 			Target: gr.Data,
 		}
 	case "SRV":
-		// SRV records are complex, using RR as fallback for now
-		fallthrough
+		// GoDaddy returns the SRV target in Data and the remaining fields separately
+		if gr.Service == "" || gr.Protocol == "" || gr.Port == 0 || gr.Data == "" {
+			// Missing required fields, fallback to RR
+			return libdns.RR{
+				Name: gr.Name,
+				TTL:  ttl,
+				Type: gr.Type,
+				Data: gr.Data,
+			}
+		}
+		return libdns.SRV{
+			Service:   strings.TrimPrefix(gr.Service, "_"),
+			Transport: strings.TrimPrefix(gr.Protocol, "_"),
+			Name:      gr.Name,
+			TTL:       ttl,
+			Priority:  uint16(gr.Priority),
+			Weight:    uint16(gr.Weight),
+			Port:      uint16(gr.Port),
+			Target:    gr.Data,
+		}
+	case "PTR":
+		// libdns has no PTR type; the RR carries the target hostname as its data.
+		// GoDaddy does not host reverse zones, so this only covers PTR records
+		// returned unexpectedly.
+		return libdns.RR{
+			Name: gr.Name,
+			TTL:  ttl,
+			Type: "PTR",
+			Data: gr.Data,
+		}
+	case "CAA":
+		flags, tag, value, ok := parseCAA(gr.Data)
+		if !ok {
+			// Malformed CAA data, fallback to RR
+			return libdns.RR{
+				Name: gr.Name,
+				TTL:  ttl,
+				Type: gr.Type,
+				Data: gr.Data,
+			}
+		}
+		return libdns.CAA{
+			Name:  gr.Name,
+			TTL:   ttl,
+			Flags: flags,
+			Tag:   tag,
+			Value: value,
+		}
+	case "HTTPS", "SVCB":
+		// Parsed from the canonical data, as the scheme and port are carried
+		// by the name; unrecognized params or names are returned as RR
+		svcb, ok := parseSVCB(gr.Data)
+		if !ok {
+			return libdns.RR{
+				Name: gr.Name,
+				TTL:  ttl,
+				Type: gr.Type,
+				Data: gr.Data,
+			}
+		}
+		rr := libdns.RR{
+			Name: gr.Name,
+			TTL:  ttl,
+			Type: strings.ToUpper(gr.Type),
+			Data: svcb.String(),
+		}
+		if binding, err := rr.Parse(); err == nil {
+			return binding
+		}
+		return rr
+	case "TLSA", "NAPTR", "DS", "SSHFP":
+		// libdns has no types for these, so they are returned as RR with
+		// their data in canonical form; malformed data is returned unchanged
+		data, _ := canonicalData(gr.Type, gr.Data)
+		return libdns.RR{
+			Name: gr.Name,
+			TTL:  ttl,
+			Type: gr.Type,
+			Data: data,
+		}
 	default:
 		return libdns.RR{
 			Name: gr.Name,
@@ -159,181 +734,2042 @@ func convertToLibdnsRecord(gr godaddyRecord) libdns.Record {
 	}
 }
 
-// GetRecords lists all the records in the zone.
-func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
-	client := p.getHTTPClient()
-	domain := getDomain(zone)
-	var records []libdns.Record
-
-	// Get all DNS records for the domain (most domains don't have enough records to require pagination)
-	url := fmt.Sprintf("%s/v1/domains/%s/records", p.getApiHost(), domain)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// parseCAA parses CAA data in the form `flags tag "value"`, e.g.
+// `0 issue "letsencrypt.org"`. The value may be unquoted, and a quoted
+// value may contain spaces as well as escaped quotes and backslashes.
+func parseCAA(data string) (uint8, string, string, bool) {
+	flagsStr, rest, ok := strings.Cut(strings.TrimSpace(data), " ")
+	if !ok {
+		return 0, "", "", false
+	}
+	flags, err := strconv.ParseUint(flagsStr, 10, 8)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return 0, "", "", false
 	}
-	p.setCommonHeaders(req)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+	tag, value, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || tag == "" {
+		return 0, "", "", false
 	}
-	defer resp.Body.Close()
 
-	// Read response body for error handling
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, `"`) {
+		if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+			return 0, "", "", false
+		}
+		value = unescapeQuoted(value[1 : len(value)-1])
+	}
+
+	return uint8(flags), tag, value, true
+}
+
+// formatCAA serializes CAA fields in the form GoDaddy expects, quoting the value
+func formatCAA(flags uint8, tag, value string) string {
+	return fmt.Sprintf(`%d %s "%s"`, flags, tag, escapeQuoted(value))
+}
+
+// canonicalData returns the data of TLSA, NAPTR, DS and SSHFP records, which
+// libdns has no structured types for, in canonical presentation form. If the
+// data is malformed, it is returned unchanged along with false. The data of
+// other types is returned unchanged.
+func canonicalData(recType, data string) (string, bool) {
+	switch strings.ToUpper(recType) {
+	case "TLSA":
+		if usage, selector, matchingType, cert, ok := parseTLSA(data); ok {
+			return formatTLSA(usage, selector, matchingType, cert), true
+		}
+	case "NAPTR":
+		if naptr, ok := parseNAPTR(data); ok {
+			return naptr.String(), true
+		}
+	case "DS":
+		if ds, ok := parseDS(data); ok {
+			return ds.String(), true
+		}
+	case "SSHFP":
+		if sshfp, ok := parseSSHFP(data); ok {
+			return sshfp.String(), true
+		}
+	case "HTTPS", "SVCB":
+		if svcb, ok := parseSVCB(data); ok {
+			return svcb.String(), true
+		}
+	default:
+		return data, true
 	}
+	return data, false
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed: status %d, body: %s", resp.StatusCode, string(bodyBytes))
+// parseTLSA parses TLSA data in the form `usage selector matching-type data`,
+// e.g. `3 1 1 0123ABCD`. The certificate association data must be hex and may
+// be split by whitespace; it is returned joined but otherwise unchanged.
+func parseTLSA(data string) (uint8, uint8, uint8, string, bool) {
+	fields := strings.Fields(data)
+	if len(fields) < 4 {
+		return 0, 0, 0, "", false
 	}
 
-	var resultObj []godaddyRecord
-	if err := json.Unmarshal(bodyBytes, &resultObj); err != nil {
-		return nil, fmt.Errorf("failed to parse response JSON: %w", err)
+	var params [3]uint8
+	for i := range params {
+		v, err := strconv.ParseUint(fields[i], 10, 8)
+		if err != nil {
+			return 0, 0, 0, "", false
+		}
+		params[i] = uint8(v)
 	}
 
-	// convert all records to libdns format
-	for _, record := range resultObj {
-		records = append(records, convertToLibdnsRecord(record))
+	cert := strings.Join(fields[3:], "")
+	if _, err := hex.DecodeString(cert); err != nil {
+		return 0, 0, 0, "", false
 	}
 
-	return records, nil
+	return params[0], params[1], params[2], cert, true
 }
 
-// convertFromLibdnsRecord converts a libdns Record to GoDaddy API format
-func convertFromLibdnsRecord(record libdns.Record, zone string) (godaddyRecord, error) {
-	rr := record.RR()
+// formatTLSA serializes TLSA fields in presentation format
+func formatTLSA(usage, selector, matchingType uint8, cert string) string {
+	return fmt.Sprintf("%d %d %d %s", usage, selector, matchingType, cert)
+}
 
-	// Ensure minimum TTL of 600 seconds as required by GoDaddy
-	ttlSeconds := int(rr.TTL / time.Second)
-	if ttlSeconds < 600 {
-		ttlSeconds = 600
-	}
+// sshfpAlgorithms lists the known SSHFP key algorithms: RSA, DSA, ECDSA,
+// Ed25519 and Ed448
+var sshfpAlgorithms = map[uint8]bool{1: true, 2: true, 3: true, 4: true, 6: true}
 
-	return godaddyRecord{
-		Type: rr.Type,
-		Name: getRecordName(zone, rr.Name),
-		Data: rr.Data,
-		TTL:  ttlSeconds,
-	}, nil
+// sshfpFingerprintLengths maps the SSHFP fingerprint types to the length of
+// their fingerprint in bytes
+var sshfpFingerprintLengths = map[uint8]int{
+	1: 20, // SHA-1
+	2: 32, // SHA-256
 }
 
-// AppendRecords adds records to the zone. It returns the records that were added.
-func (p *Provider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	var appendedRecords []libdns.Record
-	client := p.getHTTPClient()
+// sshfpData holds the fields of an SSHFP record
+type sshfpData struct {
+	Algorithm       uint8
+	FingerprintType uint8
+	Fingerprint     string
+}
 
-	for _, record := range records {
-		gr, err := convertFromLibdnsRecord(record, zone)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert record: %w", err)
-		}
+// parseSSHFP parses SSHFP data in the form `algorithm type fingerprint`, e.g.
+// `4 2 123456789ABCDEF...`. The algorithm and fingerprint type must be known
+// and the fingerprint must be hex of the length its type calls for; it may be
+// split by whitespace and is returned joined but otherwise unchanged.
+func parseSSHFP(data string) (sshfpData, bool) {
+	fields := strings.Fields(data)
+	if len(fields) < 3 {
+		return sshfpData{}, false
+	}
 
-		data, err := json.Marshal([]godaddyRecord{gr})
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal record data: %w", err)
-		}
+	algorithm, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil || !sshfpAlgorithms[uint8(algorithm)] {
+		return sshfpData{}, false
+	}
+	fingerprintType, err := strconv.ParseUint(fields[1], 10, 8)
+	if err != nil {
+		return sshfpData{}, false
+	}
+	length, ok := sshfpFingerprintLengths[uint8(fingerprintType)]
+	if !ok {
+		return sshfpData{}, false
+	}
 
-		url := fmt.Sprintf("%s/v1/domains/%s/records/%s/%s",
-			p.getApiHost(), getDomain(zone), gr.Type, gr.Name)
+	fingerprint := strings.Join(fields[2:], "")
+	decoded, err := hex.DecodeString(fingerprint)
+	if err != nil || len(decoded) != length {
+		return sshfpData{}, false
+	}
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(data))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-		p.setCommonHeaders(req)
-		req.Header.Set("Content-Type", "application/json")
+	return sshfpData{
+		Algorithm:       uint8(algorithm),
+		FingerprintType: uint8(fingerprintType),
+		Fingerprint:     fingerprint,
+	}, true
+}
 
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to execute request: %w", err)
-		}
+// String serializes the SSHFP fields in presentation format
+func (f sshfpData) String() string {
+	return fmt.Sprintf("%d %d %s", f.Algorithm, f.FingerprintType, f.Fingerprint)
+}
 
-		// Read response for better error handling
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
+// dsDigestLengths maps the DS digest types to the length of their digest in bytes
+var dsDigestLengths = map[uint8]int{
+	1: 20, // SHA-1
+	2: 32, // SHA-256
+	4: 48, // SHA-384
+}
 
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("failed to append record %s.%s: status %d, body: %s",
-				gr.Name, getDomain(zone), resp.StatusCode, string(bodyBytes))
-		}
+// dsData holds the fields of a DS record
+type dsData struct {
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+	Digest     string
+}
+
+// parseDS parses DS data in the form `key-tag algorithm digest-type digest`,
+// e.g. `60485 13 2 D4B7D520...`. The digest must be hex of the length its
+// digest type calls for and may be split by whitespace; it is returned joined
+// but otherwise unchanged.
+func parseDS(data string) (dsData, bool) {
+	fields := strings.Fields(data)
+	if len(fields) < 4 {
+		return dsData{}, false
+	}
+
+	keyTag, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return dsData{}, false
+	}
+	algorithm, err := strconv.ParseUint(fields[1], 10, 8)
+	if err != nil || algorithm == 0 {
+		return dsData{}, false
+	}
+	digestType, err := strconv.ParseUint(fields[2], 10, 8)
+	if err != nil || digestType == 0 {
+		return dsData{}, false
+	}
 
-		appendedRecords = append(appendedRecords, record)
+	digest := strings.Join(fields[3:], "")
+	decoded, err := hex.DecodeString(digest)
+	if err != nil || len(decoded) == 0 {
+		return dsData{}, false
+	}
+	if length, ok := dsDigestLengths[uint8(digestType)]; ok && len(decoded) != length {
+		return dsData{}, false
 	}
 
-	return appendedRecords, nil
+	return dsData{
+		KeyTag:     uint16(keyTag),
+		Algorithm:  uint8(algorithm),
+		DigestType: uint8(digestType),
+		Digest:     digest,
+	}, true
 }
 
-// SetRecords sets the records in the zone, either by updating existing records
-// or creating new ones. It returns the updated records.
-func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	return p.AppendRecords(ctx, zone, records)
+// String serializes the DS fields in presentation format
+func (d dsData) String() string {
+	return fmt.Sprintf("%d %d %d %s", d.KeyTag, d.Algorithm, d.DigestType, d.Digest)
 }
 
-// DeleteRecords deletes the records from the zone.
-func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	currentRecords, err := p.GetRecords(ctx, zone)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current records: %w", err)
-	}
+// naptrData holds the fields of a NAPTR record
+type naptrData struct {
+	Order       uint16
+	Preference  uint16
+	Flags       string
+	Service     string
+	Regexp      string
+	Replacement string
+}
 
-	var deletedRecords []libdns.Record
-	client := p.getHTTPClient()
+// parseNAPTR parses NAPTR data in the form
+// `order preference "flags" "service" "regexp" replacement`, e.g.
+// `100 10 "u" "E2U+sip" "!^.*$!sip:info@example.com!" .`. The flags,
+// service and regexp may be unquoted if they contain no spaces, and quoted
+// values may contain escaped quotes and backslashes.
+func parseNAPTR(data string) (naptrData, bool) {
+	fields, ok := splitQuoted(data)
+	if !ok || len(fields) != 6 {
+		return naptrData{}, false
+	}
 
-	// Find records that actually exist in the zone
-	for _, record := range records {
-		recordRR := record.RR()
-		recordName := getRecordName(zone, recordRR.Name)
-
-		for _, current := range currentRecords {
-			currentRR := current.RR()
-			if currentRR.Type == recordRR.Type &&
-				getRecordName(zone, currentRR.Name) == recordName {
-				deletedRecords = append(deletedRecords, current)
-				break
-			}
-		}
+	order, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return naptrData{}, false
+	}
+	preference, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return naptrData{}, false
+	}
+	if fields[5] == "" {
+		return naptrData{}, false
 	}
 
-	// Delete verified records with individual API calls
-	for _, record := range deletedRecords {
-		rr := record.RR()
-		recordName := getRecordName(zone, rr.Name)
+	return naptrData{
+		Order:       uint16(order),
+		Preference:  uint16(preference),
+		Flags:       fields[2],
+		Service:     fields[3],
+		Regexp:      fields[4],
+		Replacement: fields[5],
+	}, true
+}
 
-		url := fmt.Sprintf("%s/v1/domains/%s/records/%s/%s",
-			p.getApiHost(), getDomain(zone), rr.Type, recordName)
+// String serializes the NAPTR fields in presentation format, quoting the
+// flags, service and regexp
+func (n naptrData) String() string {
+	return fmt.Sprintf(`%d %d "%s" "%s" "%s" %s`, n.Order, n.Preference,
+		escapeQuoted(n.Flags), escapeQuoted(n.Service), escapeQuoted(n.Regexp), n.Replacement)
+}
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create delete request: %w", err)
-		}
-		p.setCommonHeaders(req)
+// svcParamKeys maps the SvcParamKeys registered for HTTPS and SVCB records to
+// their numbers, which order them in presentation format. Other keys are
+// only accepted in the generic form keyNNNNN.
+var svcParamKeys = map[string]int{
+	"mandatory":       0,
+	"alpn":            1,
+	"no-default-alpn": 2,
+	"port":            3,
+	"ipv4hint":        4,
+	"ech":             5,
+	"ipv6hint":        6,
+	"dohpath":         7,
+	"ohttp":           8,
+}
 
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to execute delete request: %w", err)
-		}
+// svcbData holds the fields of an HTTPS or SVCB record
+type svcbData struct {
+	Priority uint16
+	Target   string
+	Params   libdns.SvcParams
+}
 
-		// Read response for better error handling
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
+// parseSVCB parses HTTPS or SVCB data in the form `priority target [params]`,
+// e.g. `1 . alpn=h2,h3 ipv4hint=192.0.2.1`. The params must use registered
+// keys or the generic form keyNNNNN, and the values of port, ipv4hint and
+// ipv6hint must be a port number and IPv4 and IPv6 addresses respectively.
+func parseSVCB(data string) (svcbData, bool) {
+	fields := strings.Fields(data)
+	if len(fields) < 2 {
+		return svcbData{}, false
+	}
 
-		if resp.StatusCode != http.StatusNoContent {
-			return nil, fmt.Errorf("failed to delete record %s.%s: status %d, body: %s",
-				recordName, getDomain(zone), resp.StatusCode, string(bodyBytes))
+	priority, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return svcbData{}, false
+	}
+	target := fields[1]
+	if target != "." && (target == "@" || validateName(strings.TrimSuffix(target, ".")) != nil) {
+		return svcbData{}, false
+	}
+
+	// The params follow the target and may hold quoted values with spaces
+	rest := strings.TrimSpace(data)
+	for range 2 {
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			rest = strings.TrimLeftFunc(rest[i:], unicode.IsSpace)
+		} else {
+			rest = ""
+		}
+	}
+	params, err := libdns.ParseSvcParams(rest)
+	if err != nil {
+		return svcbData{}, false
+	}
+	for key, values := range params {
+		if _, ok := svcParamNumber(key); !ok || !validSvcParam(key, values) {
+			return svcbData{}, false
+		}
+	}
+
+	return svcbData{Priority: uint16(priority), Target: target, Params: params}, true
+}
+
+// svcParamNumber returns the number of a SvcParamKey, reporting false if the
+// key is neither registered nor in the generic form keyNNNNN
+func svcParamNumber(key string) (int, bool) {
+	if number, ok := svcParamKeys[key]; ok {
+		return number, true
+	}
+	digits, ok := strings.CutPrefix(key, "key")
+	if !ok || digits == "" {
+		return 0, false
+	}
+	number, err := strconv.ParseUint(digits, 10, 16)
+	return int(number), err == nil
+}
+
+// validSvcParam checks the values of the SvcParams whose format is known
+func validSvcParam(key string, values []string) bool {
+	switch key {
+	case "no-default-alpn":
+		return len(values) == 0 || (len(values) == 1 && values[0] == "")
+	case "alpn":
+		return len(values) > 0 && !slices.Contains(values, "")
+	case "port":
+		if len(values) != 1 {
+			return false
+		}
+		_, err := strconv.ParseUint(values[0], 10, 16)
+		return err == nil
+	case "ipv4hint", "ipv6hint":
+		if len(values) == 0 {
+			return false
+		}
+		for _, value := range values {
+			ip, err := netip.ParseAddr(value)
+			if err != nil || ip.Is4() != (key == "ipv4hint") || ip.Is4In6() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String serializes the HTTPS or SVCB fields in presentation format, with the
+// params ordered by key number
+func (d svcbData) String() string {
+	keys := slices.SortedFunc(maps.Keys(d.Params), func(a, b string) int {
+		numberA, _ := svcParamNumber(a)
+		numberB, _ := svcParamNumber(b)
+		return cmp.Compare(numberA, numberB)
+	})
+
+	data := fmt.Sprintf("%d %s", d.Priority, d.Target)
+	for _, key := range keys {
+		data += " " + libdns.SvcParams{key: d.Params[key]}.String()
+	}
+	return data
+}
+
+// splitQuoted splits data on whitespace, treating double-quoted strings as
+// single fields. Quotes are removed and escaped characters inside them are
+// unescaped. It reports false if a quoted string is not terminated.
+func splitQuoted(data string) ([]string, bool) {
+	var fields []string
+	var field strings.Builder
+	inField, quoted, escaped := false, false, false
+
+	for _, r := range data {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			if quoted {
+				quoted = false
+			} else {
+				quoted, inField = true, true
+			}
+		case !quoted && unicode.IsSpace(r):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if quoted {
+		return nil, false
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+
+	return fields, true
+}
+
+// escapeQuoted escapes backslashes and double quotes for use inside a quoted string
+func escapeQuoted(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// unescapeQuoted reverses escapeQuoted
+func unescapeQuoted(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if !escaped && r == '\\' {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// GetRecords lists all the records in the zone. A zone without records yields
+// an empty, non-nil slice; a nil slice is only returned along with an error.
+//
+// If the context is done while paging through the zone, the records fetched
+// so far are returned along with the context error.
+func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	resultObj, err := p.getRecords(ctx, zone)
+	return listedRecords(p.returnedRecords(zone, resultObj), err)
+}
+
+// listedRecords returns the records listed by a successful request as a
+// non-nil slice, so that callers can tell an empty result from a failure
+func listedRecords(records []libdns.Record, err error) ([]libdns.Record, error) {
+	if records == nil && err == nil {
+		records = []libdns.Record{}
+	}
+	return records, err
+}
+
+// returnedRecords converts the records listed from the zone to the records
+// returned to the caller, applying ExcludeSystemRecords, SortRecords and
+// AbsoluteNames
+func (p *Provider) returnedRecords(zone string, grs []godaddyRecord) []libdns.Record {
+	return convertToLibdnsRecords(p.absoluteNames(zone, p.sortRecords(p.filterSystemRecords(zone, grs))))
+}
+
+// absoluteNames returns the records with fully qualified names if
+// AbsoluteNames is set
+func (p *Provider) absoluteNames(zone string, grs []godaddyRecord) []godaddyRecord {
+	if !p.AbsoluteNames {
+		return grs
+	}
+	fqdn := toASCII(strings.TrimSuffix(zone, ".")) + "."
+	absolute := make([]godaddyRecord, len(grs))
+	for i, gr := range grs {
+		gr.Name = libdns.AbsoluteName(gr.Name, fqdn)
+		absolute[i] = gr
+	}
+	return absolute
+}
+
+// filterSystemRecords removes the records managed by GoDaddy if
+// ExcludeSystemRecords is set
+func (p *Provider) filterSystemRecords(zone string, grs []godaddyRecord) []godaddyRecord {
+	if !p.ExcludeSystemRecords {
+		return grs
+	}
+	var filtered []godaddyRecord
+	for _, gr := range grs {
+		if !isSystemRecord(zone, gr) {
+			filtered = append(filtered, gr)
+		}
+	}
+	return filtered
+}
+
+// sortRecords orders the records by name, type and data if SortRecords is set,
+// keeping the order of records that compare equal
+func (p *Provider) sortRecords(grs []godaddyRecord) []godaddyRecord {
+	if !p.SortRecords {
+		return grs
+	}
+	slices.SortStableFunc(grs, func(a, b godaddyRecord) int {
+		return cmp.Or(
+			strings.Compare(a.Name, b.Name),
+			strings.Compare(a.Type, b.Type),
+			strings.Compare(a.Data, b.Data),
+		)
+	})
+	return grs
+}
+
+// isSystemRecord reports whether a record is created and managed by GoDaddy
+// rather than by the owner of the zone:
+//
+//   - SOA records
+//   - the NS records at the apex of the registered domain, see isApexNS
+//   - the A record at the apex GoDaddy points at its parking page, whose data
+//     is "Parked"
+//   - the _domainconnect CNAME record GoDaddy points at its Domain Connect
+//     service on domaincontrol.com
+//
+// The last three only exist at the registered domain, never within a
+// delegated zone.
+func isSystemRecord(zone string, gr godaddyRecord) bool {
+	recType := strings.ToUpper(gr.Type)
+	if recType == "SOA" {
+		return true
+	}
+	if getZonePrefix(zone) != "" {
+		return false
+	}
+	switch recType {
+	case "NS":
+		return isApexNS(zone, gr)
+	case "A":
+		return gr.Name == "@" && strings.EqualFold(gr.Data, "Parked")
+	case "CNAME":
+		target := strings.ToLower(strings.TrimSuffix(gr.Data, "."))
+		return strings.EqualFold(gr.Name, "_domainconnect") && strings.HasSuffix(target, ".domaincontrol.com")
+	}
+	return false
+}
+
+// GetRecordsByTypeName lists the records of the given type and name in the zone,
+// using GoDaddy's scoped endpoint instead of fetching the whole zone. The name may
+// be relative or fully qualified. If name is empty, all records of the type are
+// returned. As with GetRecords, the slice is only nil along with an error.
+func (p *Provider) GetRecordsByTypeName(ctx context.Context, zone, recType, name string) ([]libdns.Record, error) {
+	if name != "" {
+		name = getRecordName(zone, name)
+	}
+
+	resultObj, err := p.listRecords(ctx, zone, recType, name)
+	return listedRecords(p.returnedRecords(zone, resultObj), err)
+}
+
+// GetRecordsByName lists the records of all types at the given name in the
+// zone, e.g. every record at "_dmarc" or the SRV records at "_sip._tcp".
+// GoDaddy cannot filter by name alone, so the zone is fetched (or served from
+// the cache, see CacheTTL) and filtered. The name may be relative or fully
+// qualified; "@" or an empty name selects the zone apex.
+func (p *Provider) GetRecordsByName(ctx context.Context, zone, name string) ([]libdns.Record, error) {
+	name = getRecordName(zone, name)
+
+	current, err := p.getRecords(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []godaddyRecord
+	for _, gr := range current {
+		// Compared with the libdns name, which includes the service and
+		// protocol of SRV records
+		if strings.EqualFold(getRecordName(zone, convertToLibdnsRecord(gr).RR().Name), name) {
+			matching = append(matching, gr)
+		}
+	}
+	return listedRecords(p.returnedRecords(zone, matching), nil)
+}
+
+// convertToLibdnsRecords converts GoDaddy API records to libdns Records
+func convertToLibdnsRecords(grs []godaddyRecord) []libdns.Record {
+	var records []libdns.Record
+	for _, record := range grs {
+		records = append(records, convertToLibdnsRecord(record))
+	}
+	return records
+}
+
+// getRecords fetches all the records in the zone in GoDaddy API format.
+// With CacheTTL set, the records are served from the zone cache when possible.
+func (p *Provider) getRecords(ctx context.Context, zone string) ([]godaddyRecord, error) {
+	if p.CacheTTL <= 0 {
+		return p.listRecords(ctx, zone, "", "")
+	}
+
+	key := toASCII(strings.TrimSuffix(zone, "."))
+	state := p.getState()
+	if records, ok := state.cachedRecords(key, p.getNow()); ok {
+		return records, nil
+	}
+
+	records, err := p.listRecords(ctx, zone, "", "")
+	if err != nil {
+		return records, err
+	}
+	state.cacheRecords(key, records, p.getNow().Add(p.CacheTTL))
+	return records, nil
+}
+
+// listRecords fetches the records in the zone in GoDaddy API format, optionally
+// scoped to a type or a type and name, following the offset/limit pagination
+// until a partial page is returned. If the context is done between pages, the
+// records fetched so far are returned along with the context error. For a
+// delegated zone, only the records within it are returned.
+func (p *Provider) listRecords(ctx context.Context, zone, recType, name string) ([]godaddyRecord, error) {
+	domain := getDomain(zone)
+	pageSize := p.getPageSize()
+	var records []godaddyRecord
+
+	path := fmt.Sprintf("%s/v1/domains/%s/records", p.getApiHost(), domain)
+	if recType != "" {
+		path += "/" + recType
+		if name != "" {
+			path += "/" + toDomainName(zone, name)
+		}
+	}
+
+	for offset := 0; ; offset += pageSize {
+		if err := ctx.Err(); err != nil {
+			return records, err
+		}
+
+		url := fmt.Sprintf("%s?offset=%d&limit=%d", path, offset, pageSize)
+
+		reqCtx := ctx
+		listing, cached := cachedListing{}, false
+		if p.EnableConditionalGet {
+			if listing, cached = p.getState().cachedListing(url); cached {
+				reqCtx = withRequestHeader(ctx, "If-None-Match", listing.etag)
+			}
+		}
+
+		resp, bodyBytes, err := p.doRequest(reqCtx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		notModified := cached && resp.StatusCode == http.StatusNotModified
+		if !isSuccess(resp.StatusCode) && !notModified {
+			// A 404 to a scoped listing may only mean there are no such records
+			if recType != "" {
+				return nil, fmt.Errorf("API request failed: %w", newRecordsError(resp, bodyBytes, zone))
+			}
+			return nil, fmt.Errorf("API request failed: %w", newZoneError(resp, bodyBytes, zone))
+		}
+
+		if recType == "" && offset == 0 && p.EnableOptimisticConcurrency {
+			p.getState().setETag(domain, resp.Header.Get("ETag"))
+		}
+
+		var resultObj []godaddyRecord
+		if notModified {
+			resultObj = listing.records
+		} else {
+			if err := json.Unmarshal(bodyBytes, &resultObj); err != nil {
+				return nil, fmt.Errorf("failed to parse response JSON: %w", err)
+			}
+			if p.EnableConditionalGet {
+				p.getState().cacheListing(url, resp.Header.Get("ETag"), resultObj)
+			}
+		}
+		records = append(records, fromDomainRecords(zone, resultObj)...)
+
+		if len(resultObj) < pageSize {
+			return records, nil
+		}
+	}
+}
+
+// validateAddress checks that an A record carries an IPv4 address and an AAAA
+// record an IPv6 address, as GoDaddy rejects mismatches with an opaque error
+func validateAddress(rr libdns.RR) error {
+	recType := strings.ToUpper(rr.Type)
+	if recType != "A" && recType != "AAAA" {
+		return nil
+	}
+
+	ip, err := netip.ParseAddr(rr.Data)
+	if err != nil {
+		return fmt.Errorf("%s record %s has an invalid IP address %q: %w", recType, rr.Name, rr.Data, err)
+	}
+	if recType == "A" && !ip.Is4() {
+		return fmt.Errorf("A record %s requires an IPv4 address, got %s", rr.Name, rr.Data)
+	}
+	if recType == "AAAA" && !ip.Is6() {
+		return fmt.Errorf("AAAA record %s requires an IPv6 address, got %s", rr.Name, rr.Data)
+	}
+	return nil
+}
+
+// defaultTTL returns the TTL for records of the given type written without one,
+// or zero if neither TypeTTLDefaults nor DefaultTTL sets one
+func (p *Provider) defaultTTL(recType string) time.Duration {
+	for t, ttl := range p.TypeTTLDefaults {
+		if strings.EqualFold(t, recType) && ttl > 0 {
+			return ttl
+		}
+	}
+	return p.DefaultTTL
+}
+
+// recordTTL returns the TTL in seconds the record is written with for the given
+// TTL, applying the default TTLs and the TTL limits of the provider
+func (p *Provider) recordTTL(gr godaddyRecord, ttl time.Duration) (int, error) {
+	if ttl == 0 {
+		ttl = p.defaultTTL(gr.Type)
+	}
+	if ttl == 0 || (p.enforceMinTTL() && ttl < p.getMinTTL()) {
+		ttl = p.getMinTTL()
+	}
+	seconds := int(ttl / time.Second)
+
+	maxTTL := p.getMaxTTL()
+	if time.Duration(seconds)*time.Second > maxTTL {
+		if !p.ClampMaxTTL {
+			return 0, fmt.Errorf("TTL of %s record %s (%ds) exceeds the maximum of %ds",
+				gr.Type, gr.Name, seconds, int(maxTTL/time.Second))
+		}
+		seconds = int(maxTTL / time.Second)
+	}
+	return seconds, nil
+}
+
+// convertRecord converts a libdns Record to GoDaddy API format, applying the
+// provider's default TTL and TTL limits, and validates the result
+func (p *Provider) convertRecord(record libdns.Record, zone string) (godaddyRecord, error) {
+	gr, err := convertFromLibdnsRecord(record, zone)
+	if err != nil {
+		return godaddyRecord{}, err
+	}
+
+	// Replace the TTL set by convertFromLibdnsRecord, which always enforces
+	// GoDaddy's default minimum
+	if gr.TTL, err = p.recordTTL(gr, record.RR().TTL); err != nil {
+		return godaddyRecord{}, err
+	}
+
+	if err := validateRecord(gr); err != nil {
+		return godaddyRecord{}, err
+	}
+	if isApexNS(zone, gr) {
+		return godaddyRecord{}, fmt.Errorf("NS record %s: %w; change them in the nameserver settings of %s",
+			gr.Name, ErrApexNS, getDomain(zone))
+	}
+
+	return gr, nil
+}
+
+// isApexNS reports whether a record is one of the NS records at the apex of
+// the registered domain, i.e. its nameservers. The apex of a delegated zone is
+// a name within the registered domain, whose NS records delegate it and can be
+// changed like any other record.
+func isApexNS(zone string, gr godaddyRecord) bool {
+	return strings.EqualFold(gr.Type, "NS") && gr.Name == "@" && getZonePrefix(zone) == ""
+}
+
+// convertFromLibdnsRecord converts a libdns Record to GoDaddy API format
+func convertFromLibdnsRecord(record libdns.Record, zone string) (godaddyRecord, error) {
+	// Parse opaque RR values so that structured types are handled the same way
+	if rr, ok := record.(libdns.RR); ok {
+		// Parsing an address derives the type from the IP, so check that the
+		// given type matches the IP family first
+		if err := validateAddress(rr); err != nil {
+			return godaddyRecord{}, err
+		}
+		if parsed, err := rr.Parse(); err == nil {
+			record = parsed
+		}
+	}
+	if addr, ok := record.(libdns.Address); ok && !addr.IP.IsValid() {
+		return godaddyRecord{}, fmt.Errorf("address record %s has no IP address", addr.Name)
+	}
+	rr := record.RR()
+
+	switch strings.ToUpper(rr.Type) {
+	case "PTR":
+		// GoDaddy's API cannot manage reverse zones
+		return godaddyRecord{}, fmt.Errorf("PTR record %s: %w", rr.Name, ErrUnsupportedRecordType)
+	case "ALIAS", "ANAME":
+		// GoDaddy has no apex alias pseudo-record, neither in the API nor in
+		// its DNS management; its domain forwarding is a separate HTTP service
+		return godaddyRecord{}, fmt.Errorf("%s record %s: %w: GoDaddy has no ALIAS or ANAME records; "+
+			"use A/AAAA records at the zone apex instead", strings.ToUpper(rr.Type), rr.Name, ErrUnsupportedRecordType)
+	}
+
+	// Ensure minimum TTL of 600 seconds as required by GoDaddy
+	ttlSeconds := int(rr.TTL / time.Second)
+	if ttlSeconds < int(defaultMinTTL/time.Second) {
+		ttlSeconds = int(defaultMinTTL / time.Second)
+	}
+
+	switch rec := record.(type) {
+	case libdns.SRV:
+		// GoDaddy expects the SRV fields separately, with the target in Data
+		return godaddyRecord{
+			Type:     "SRV",
+			Name:     getRecordName(zone, rec.Name),
+			Data:     rec.Target,
+			TTL:      ttlSeconds,
+			Priority: int(rec.Priority),
+			Weight:   int(rec.Weight),
+			Port:     int(rec.Port),
+			Service:  "_" + rec.Service,
+			Protocol: "_" + rec.Transport,
+		}, nil
+	case libdns.MX:
+		// GoDaddy expects the preference in the priority field, with the
+		// target alone in Data
+		return godaddyRecord{
+			Type:     "MX",
+			Name:     getRecordName(zone, rec.Name),
+			Data:     rec.Target,
+			TTL:      ttlSeconds,
+			Priority: int(rec.Preference),
+		}, nil
+	case libdns.NS:
+		// GoDaddy stores and returns nameserver names without the trailing dot
+		return godaddyRecord{
+			Type: "NS",
+			Name: getRecordName(zone, rec.Name),
+			Data: strings.TrimSuffix(rec.Target, "."),
+			TTL:  ttlSeconds,
+		}, nil
+	case libdns.CAA:
+		return godaddyRecord{
+			Type: "CAA",
+			Name: getRecordName(zone, rec.Name),
+			Data: formatCAA(rec.Flags, rec.Tag, rec.Value),
+			TTL:  ttlSeconds,
+		}, nil
+	}
+
+	data, ok := canonicalData(rr.Type, rr.Data)
+	if !ok {
+		return godaddyRecord{}, fmt.Errorf("%s record %s has malformed data %q",
+			strings.ToUpper(rr.Type), rr.Name, rr.Data)
+	}
+
+	return godaddyRecord{
+		Type: rr.Type,
+		Name: getRecordName(zone, rr.Name),
+		Data: data,
+		TTL:  ttlSeconds,
+	}, nil
+}
+
+// AppendRecords adds records to the zone. It returns the records that were added,
+// as stored by GoDaddy: the added records are read back with one request, so
+// the returned records reflect e.g. the enforced minimum TTL. Existing records
+// are never changed, so appending a value to a type and name that already has
+// records keeps the existing values.
+//
+// All records are added with a single PATCH request, which appends to the zone
+// unlike GoDaddy's PUT endpoints that replace all records of a type and name.
+// Records sharing a type and name are thus added together, and each keeps
+// its value. If GoDaddy rejects the batch as invalid (HTTP 400 or 422),
+// nothing has been added and the records are retried one type and name at a
+// time (or up to MaxConcurrency at a time) until one fails.
+//
+// Whenever an error is returned after some records were added, be it a
+// rejected record, a failed request or a done context, the records added so
+// far are returned along with the error, so that callers know the state of
+// the zone.
+func (p *Provider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	var grs []godaddyRecord
+	for _, record := range records {
+		gr, err := p.convertRecord(record, zone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert record: %w", err)
+		}
+		grs = append(grs, gr)
+	}
+	if err := validateCNAMEConflicts(grs); err != nil {
+		return nil, err
+	}
+
+	if len(grs) == 0 {
+		return nil, nil
+	}
+
+	if len(p.RecordLimits) > 0 {
+		current, err := p.getRecords(ctx, zone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch records of %s: %w", getDomain(zone), err)
+		}
+		if err := p.checkRecordLimits(zone, current, grs, false); err != nil {
+			return nil, err
+		}
+	}
+
+	err := p.patchRecords(ctx, zone, grs)
+	if err == nil {
+		return p.persistedRecords(ctx, zone, grs), nil
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) ||
+		(apiErr.StatusCode != http.StatusBadRequest && apiErr.StatusCode != http.StatusUnprocessableEntity) {
+		return nil, fmt.Errorf("failed to append records to %s: %w", getDomain(zone), err)
+	}
+
+	// The batch was rejected as a whole, add the records one type and name at
+	// a time to find out which ones are affected. The values of a type and
+	// name are still sent together, so they are all added or all rejected.
+	var batches [][]int
+	index := make(map[string]int)
+	for i, gr := range grs {
+		key := strings.ToUpper(gr.Type) + "/" + strings.ToLower(gr.Name)
+		batch, ok := index[key]
+		if !ok {
+			batch = len(batches)
+			index[key] = batch
+			batches = append(batches, nil)
+		}
+		batches[batch] = append(batches[batch], i)
+	}
+
+	done, err := p.runConcurrently(ctx, len(batches), func(ctx context.Context, i int) error {
+		var batch []godaddyRecord
+		for _, j := range batches[i] {
+			batch = append(batch, grs[j])
+		}
+		if err := p.patchRecords(ctx, zone, batch); err != nil {
+			return fmt.Errorf("failed to append records %s.%s (%s): %w",
+				batch[0].Name, getDomain(zone), batch[0].Type, err)
+		}
+		return nil
+	})
+
+	added := make([]bool, len(grs))
+	for i, batch := range batches {
+		for _, j := range batch {
+			added[j] = done[i]
+		}
+	}
+	var appended []godaddyRecord
+	for i, gr := range grs {
+		if added[i] {
+			appended = append(appended, gr)
+		}
+	}
+
+	return p.persistedRecords(ctx, zone, appended), err
+}
+
+// AppendRecordsIfAbsent adds the records that are not already in the zone,
+// comparing records by RecordKey, and returns only the records that were
+// created. The current records are fetched once per type and name, so
+// re-running it with the same records is safe and sends no modifying request.
+func (p *Provider) AppendRecordsIfAbsent(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	_, missing, err := p.presentRecords(ctx, zone, records)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.AppendRecords(ctx, zone, missing)
+}
+
+// AppendStatus is the outcome of appending a single record with
+// AppendRecordsWithStatus
+type AppendStatus struct {
+	// Record is the record as stored in the zone
+	Record libdns.Record
+	// Created reports whether the record was added, as opposed to being
+	// already present in the zone
+	Created bool
+}
+
+// AppendRecordsWithStatus adds the records that are not already in the zone
+// like AppendRecordsIfAbsent, but returns a status for each of the given
+// records in order, reporting whether it was created or already existed. A
+// record given more than once is only created for its first occurrence. If
+// some records could not be added, the statuses of the records that are in
+// the zone are returned together with the error.
+func (p *Provider) AppendRecordsWithStatus(ctx context.Context, zone string, records []libdns.Record) ([]AppendStatus, error) {
+	present, missing, err := p.presentRecords(ctx, zone, records)
+	if err != nil {
+		return nil, err
+	}
+
+	appended, err := p.AppendRecords(ctx, zone, missing)
+	created := make(map[string]libdns.Record)
+	for _, record := range appended {
+		created[RecordKey(zone, record)] = record
+	}
+
+	statuses := make([]AppendStatus, 0, len(records))
+	reported := make(map[string]bool)
+	for i, record := range records {
+		if present[i] != nil {
+			statuses = append(statuses, AppendStatus{Record: present[i]})
+			continue
+		}
+		key := RecordKey(zone, record)
+		if stored, ok := created[key]; ok {
+			statuses = append(statuses, AppendStatus{Record: stored, Created: !reported[key]})
+			reported[key] = true
+		}
+	}
+
+	return statuses, err
+}
+
+// presentRecords returns, for each of the given records, the matching record
+// already in the zone by RecordKey, or nil if it is missing, together with the
+// missing records without duplicates. The current records are fetched once
+// per type and name.
+func (p *Provider) presentRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, []libdns.Record, error) {
+	existing := make(map[string]libdns.Record)
+	fetched := make(map[string]bool)
+	seen := make(map[string]bool)
+	present := make([]libdns.Record, len(records))
+	var missing []libdns.Record
+
+	for i, record := range records {
+		gr, err := p.convertRecord(record, zone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to convert record: %w", err)
+		}
+
+		groupKey := gr.Type + "/" + gr.Name
+		if !fetched[groupKey] {
+			current, err := p.listRecords(ctx, zone, gr.Type, gr.Name)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, cur := range current {
+				stored := convertToLibdnsRecord(cur)
+				existing[RecordKey(zone, stored)] = stored
+			}
+			fetched[groupKey] = true
+		}
+
+		key := RecordKey(zone, record)
+		if stored, ok := existing[key]; ok {
+			present[i] = stored
+			continue
+		}
+		// Also skip duplicates within the given records
+		if !seen[key] {
+			seen[key] = true
+			missing = append(missing, record)
+		}
+	}
+
+	return present, missing, nil
+}
+
+// checkRecordLimits fails with ErrRecordLimit if writing the records would
+// leave more records of a type in the zone than RecordLimits allows. With
+// replace, the written records replace the current records of their types
+// and names, otherwise they are added to them. A type already above its limit
+// only fails if the write adds to it.
+func (p *Provider) checkRecordLimits(zone string, current, written []godaddyRecord, replace bool) error {
+	if len(p.RecordLimits) == 0 {
+		return nil
+	}
+
+	key := func(gr godaddyRecord) string {
+		return strings.ToUpper(gr.Type) + "/" + strings.ToLower(gr.Name)
+	}
+	replaced := make(map[string]bool)
+	if replace {
+		for _, gr := range written {
+			replaced[key(gr)] = true
+		}
+	}
+
+	before := make(map[string]int)
+	after := make(map[string]int)
+	for _, gr := range current {
+		before[strings.ToUpper(gr.Type)]++
+		if !replaced[key(gr)] {
+			after[strings.ToUpper(gr.Type)]++
+		}
+	}
+	for _, gr := range written {
+		after[strings.ToUpper(gr.Type)]++
+	}
+
+	types := slices.Sorted(maps.Keys(p.RecordLimits))
+	for _, recType := range types {
+		limit := p.RecordLimits[recType]
+		recType = strings.ToUpper(recType)
+		if count := after[recType]; count > limit && count > before[recType] {
+			return fmt.Errorf("%w: %s would hold %d %s records, more than the limit of %d",
+				ErrRecordLimit, strings.TrimSuffix(zone, "."), count, recType, limit)
+		}
+	}
+	return nil
+}
+
+// persistedRecords reads the given records back from GoDaddy, so that they
+// are returned as actually stored. A single type and name is read with the
+// scoped endpoint, several with one listing of the zone. Records that cannot
+// be read back are returned as sent.
+func (p *Provider) persistedRecords(ctx context.Context, zone string, grs []godaddyRecord) []libdns.Record {
+	var current []godaddyRecord
+	if len(grs) > 0 && ctx.Err() == nil {
+		shared := !slices.ContainsFunc(grs[1:], func(gr godaddyRecord) bool {
+			return !strings.EqualFold(gr.Type, grs[0].Type) || gr.Name != grs[0].Name
+		})
+		if shared {
+			current, _ = p.listRecords(ctx, zone, grs[0].Type, grs[0].Name)
+		} else {
+			current, _ = p.listRecords(ctx, zone, "", "")
+		}
+	}
+
+	stored := make(map[string]libdns.Record)
+	for _, gr := range current {
+		record := convertToLibdnsRecord(gr)
+		if key := RecordKey(zone, record); stored[key] == nil {
+			stored[key] = record
+		}
+	}
+
+	var records []libdns.Record
+	for _, gr := range grs {
+		record := convertToLibdnsRecord(gr)
+		if persisted, ok := stored[RecordKey(zone, record)]; ok {
+			record = persisted
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// patchRecords adds the given records to the zone with a single request
+func (p *Provider) patchRecords(ctx context.Context, zone string, records []godaddyRecord) error {
+	data, err := json.Marshal(toDomainRecords(zone, records))
+	if err != nil {
+		return fmt.Errorf("failed to marshal record data: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/domains/%s/records", p.getApiHost(), getDomain(zone))
+
+	resp, bodyBytes, err := p.doZoneRequest(ctx, zone, http.MethodPatch, url, data)
+	if err != nil {
+		return err
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return newZoneError(resp, bodyBytes, zone, records...)
+	}
+
+	return nil
+}
+
+// recordGroup holds the records sharing a single type and name, which is
+// the unit GoDaddy replaces with PUT /v1/domains/{domain}/records/{type}/{name}
+type recordGroup struct {
+	Type    string
+	Name    string
+	Records []godaddyRecord
+	Inputs  []libdns.Record
+
+	// Deleted holds the current records removed from the group by DeleteRecords
+	Deleted []godaddyRecord
+
+	// Kept holds the current records of the type and name that the group does
+	// not set, i.e. the SRV records of other services and protocols, which are
+	// written back along with the group's records
+	Kept []godaddyRecord
+}
+
+// covers reports whether a current record is one of those the group sets: a
+// record of the group's type and name and, as the name of an SRV record
+// includes its service and protocol, for SRV of one of the group's services
+// and protocols
+func (g *recordGroup) covers(zone string, gr godaddyRecord) bool {
+	if gr.Type != g.Type || getRecordName(zone, gr.Name) != g.Name {
+		return false
+	}
+	if !strings.EqualFold(gr.Type, "SRV") {
+		return true
+	}
+	return slices.ContainsFunc(g.Records, func(record godaddyRecord) bool {
+		return strings.EqualFold(record.Service, gr.Service) && strings.EqualFold(record.Protocol, gr.Protocol)
+	})
+}
+
+// keep sets the current records of the group's type and name that it does not
+// cover to be written back with it
+func (g *recordGroup) keep(zone string, current []godaddyRecord) {
+	g.Kept = nil
+	for _, gr := range current {
+		if gr.Type == g.Type && getRecordName(zone, gr.Name) == g.Name && !g.covers(zone, gr) {
+			g.Kept = append(g.Kept, gr)
+		}
+	}
+}
+
+// written returns the records replacing those of the group's type and name
+func (g *recordGroup) written() []godaddyRecord {
+	return append(slices.Clip(g.Records), g.Kept...)
+}
+
+// groupRecords converts the records to GoDaddy format and groups them by type
+// and name, preserving the order in which each group first appears. Duplicate
+// records, as identified by RecordKey, are only included once. SRV records are
+// grouped by the name GoDaddy stores them under, without their service and
+// protocol, since that is what GoDaddy replaces; see recordGroup.covers.
+func (p *Provider) groupRecords(zone string, records []libdns.Record) ([]*recordGroup, error) {
+	var groups []*recordGroup
+	index := make(map[string]*recordGroup)
+	seen := make(map[string]bool)
+	var grs []godaddyRecord
+
+	for _, record := range records {
+		key := RecordKey(zone, record)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		gr, err := p.convertRecord(record, zone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert record: %w", err)
+		}
+
+		groupKey := gr.Type + "/" + gr.Name
+		group, ok := index[groupKey]
+		if !ok {
+			group = &recordGroup{Type: gr.Type, Name: gr.Name}
+			index[groupKey] = group
+			groups = append(groups, group)
+		}
+		group.Records = append(group.Records, gr)
+		group.Inputs = append(group.Inputs, record)
+		grs = append(grs, gr)
+	}
+
+	if err := validateCNAMEConflicts(grs); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// putRecords replaces all records of the given type and name with the given records
+func (p *Provider) putRecords(ctx context.Context, zone, recType, name string, records []godaddyRecord) error {
+	data, err := json.Marshal(toDomainRecords(zone, records))
+	if err != nil {
+		return fmt.Errorf("failed to marshal record data: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/domains/%s/records/%s/%s",
+		p.getApiHost(), getDomain(zone), recType, toDomainName(zone, name))
+
+	resp, bodyBytes, err := p.doZoneRequest(ctx, zone, http.MethodPut, url, data)
+	if err != nil {
+		return err
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return newRecordsError(resp, bodyBytes, zone, records...)
+	}
+
+	return nil
+}
+
+// SetRecords sets the records in the zone, either by updating existing records
+// or creating new ones. It returns the records as they are stored in the zone
+// afterwards, i.e. with the TTL limits applied.
+//
+// Records are grouped by type and name, and each group replaces all existing
+// records of that type and name, so after the call the zone contains exactly
+// the given records for every type and name present in the input. Other
+// records in the zone are left untouched, including the SRV records of other
+// services and protocols at the same name, which GoDaddy stores under one
+// name and are written back with the group.
+//
+// The zone is fetched first, and only the groups that differ from it are
+// written, one request per group. With EnableOptimisticConcurrency, the ETag
+// of an earlier listing is kept, so changes made since are still detected.
+func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	groups, err := p.groupRecords(zone, records)
+	if err != nil {
+		return nil, err
+	}
+
+	state := p.getState()
+	domain := getDomain(zone)
+	etag := state.etag(domain)
+	// The zone is listed afresh even with CacheTTL, as skipping a group based
+	// on stale records would leave the zone unchanged while reporting success
+	current, err := p.listRecords(ctx, zone, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch records of %s: %w", domain, err)
+	}
+	if etag != "" {
+		state.setETag(domain, etag)
+	}
+
+	// The kept SRV records of other services are written back, and so count
+	// towards the limits like the group's own records
+	var desired []godaddyRecord
+	for _, group := range groups {
+		group.keep(zone, current)
+		desired = append(desired, group.written()...)
+	}
+	if err := p.checkRecordLimits(zone, current, desired, true); err != nil {
+		return nil, err
+	}
+
+	var pending []*recordGroup
+	unchanged := make(map[*recordGroup]bool)
+	for _, group := range groups {
+		if groupUnchanged(zone, group, current) {
+			unchanged[group] = true
+		} else {
+			pending = append(pending, group)
+		}
+	}
+
+	done, err := p.runConcurrently(ctx, len(pending), func(ctx context.Context, i int) error {
+		group := pending[i]
+		if err := p.putRecords(ctx, zone, group.Type, group.Name, group.written()); err != nil {
+			return fmt.Errorf("failed to set records %s.%s (%s): %w",
+				group.Name, domain, group.Type, err)
+		}
+		return nil
+	})
+
+	written := make(map[*recordGroup]bool)
+	for i, group := range pending {
+		written[group] = done[i]
+	}
+
+	var setRecords []libdns.Record
+	for _, group := range groups {
+		if unchanged[group] || written[group] {
+			setRecords = append(setRecords, convertToLibdnsRecords(group.Records)...)
+		}
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return setRecords, err
+		}
+		return nil, err
+	}
+
+	return setRecords, nil
+}
+
+// UpdateRecords replaces the values of existing records without creating new
+// ones. Records are grouped by type and name like in SetRecords, and each
+// group replaces all existing records of that type and name. Every type and
+// name is checked to exist first, with a scoped request each; if any does not,
+// nothing is written and the error names every missing record and wraps
+// ErrRecordNotFound. An SRV record only exists if its service and protocol
+// do, and the SRV records of other services at its name are written back
+// unchanged. It returns the records as written, i.e. with the TTL limits
+// applied.
+func (p *Provider) UpdateRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	groups, err := p.groupRecords(zone, records)
+	if err != nil {
+		return nil, err
+	}
+	domain := getDomain(zone)
+
+	missing := make([]bool, len(groups))
+	_, err = p.runConcurrently(ctx, len(groups), func(ctx context.Context, i int) error {
+		group := groups[i]
+		current, err := p.listRecords(ctx, zone, group.Type, group.Name)
+		if err != nil {
+			return fmt.Errorf("failed to fetch records %s.%s (%s): %w",
+				group.Name, domain, group.Type, err)
+		}
+		missing[i] = !slices.ContainsFunc(current, func(gr godaddyRecord) bool {
+			return group.covers(zone, gr)
+		})
+		group.keep(zone, current)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for i, group := range groups {
+		if missing[i] {
+			errs = append(errs, fmt.Errorf("%s.%s (%s): %w",
+				group.Name, domain, group.Type, ErrRecordNotFound))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to update records: %w", errors.Join(errs...))
+	}
+
+	done, err := p.runConcurrently(ctx, len(groups), func(ctx context.Context, i int) error {
+		group := groups[i]
+		if err := p.putRecords(ctx, zone, group.Type, group.Name, group.written()); err != nil {
+			return fmt.Errorf("failed to update records %s.%s (%s): %w",
+				group.Name, domain, group.Type, err)
+		}
+		return nil
+	})
+
+	var updated []libdns.Record
+	for i, group := range groups {
+		if done[i] {
+			updated = append(updated, convertToLibdnsRecords(group.Records)...)
+		}
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return updated, err
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// groupUnchanged reports whether the current records the group covers are
+// exactly the records of the group, with the same TTLs, so that writing the
+// group would not change the zone
+func groupUnchanged(zone string, group *recordGroup, current []godaddyRecord) bool {
+	want := make(map[string]int)
+	for _, gr := range group.Records {
+		want[RecordKey(zone, convertToLibdnsRecord(gr))+"/"+strconv.Itoa(gr.TTL)]++
+	}
+
+	count := 0
+	for _, gr := range current {
+		if !group.covers(zone, gr) {
+			continue
+		}
+		key := RecordKey(zone, convertToLibdnsRecord(gr)) + "/" + strconv.Itoa(gr.TTL)
+		if want[key] == 0 {
+			return false
+		}
+		want[key]--
+		count++
+	}
+
+	return count == len(group.Records)
+}
+
+// RecordKey returns a stable key identifying a record within the zone by its
+// type, relative name and data. GoDaddy has no record IDs, so this key is what
+// the provider uses to tell apart records sharing a type and name; callers
+// building diffs can use it to apply the same matching.
+//
+// The data is normalized so that formatting differences don't affect the key:
+// MX, SRV, NAPTR and DS records are keyed on their structured fields, trailing
+// dots are removed from target hostnames and hex data of TLSA, DS and SSHFP
+// records is compared ignoring case. The TTL is not part of the key.
+func RecordKey(zone string, record libdns.Record) string {
+	rr := record.RR()
+	return strings.ToUpper(rr.Type) + "/" + getRecordName(zone, rr.Name) + "/" + normalizeData(rr)
+}
+
+// normalizeData returns the data of the record in a canonical form
+func normalizeData(rr libdns.RR) string {
+	parsed, err := rr.Parse()
+	if err != nil {
+		return rr.Data
+	}
+
+	switch rec := parsed.(type) {
+	case libdns.MX:
+		return fmt.Sprintf("%d %s", rec.Preference, strings.TrimSuffix(rec.Target, "."))
+	case libdns.SRV:
+		return fmt.Sprintf("%d %d %d %s", rec.Priority, rec.Weight, rec.Port, strings.TrimSuffix(rec.Target, "."))
+	case libdns.CNAME:
+		return strings.TrimSuffix(rec.Target, ".")
+	case libdns.NS:
+		return strings.TrimSuffix(rec.Target, ".")
+	}
+
+	data, ok := canonicalData(rr.Type, rr.Data)
+	if !ok {
+		return rr.Data
+	}
+	switch strings.ToUpper(rr.Type) {
+	case "TLSA", "DS", "SSHFP":
+		// These consist of numbers and hex data, whose digits are case-insensitive
+		return strings.ToUpper(data)
+	}
+	return data
+}
+
+// recordMatches reports whether the current record satisfies the record
+// requested for deletion. Type and name must always match; when the requested
+// record has no data, any value of that type and name matches. Otherwise the
+// records must have the same RecordKey.
+func recordMatches(zone string, want, have libdns.Record) bool {
+	wantRR := want.RR()
+	haveRR := have.RR()
+
+	if wantRR.Type != haveRR.Type ||
+		getRecordName(zone, wantRR.Name) != getRecordName(zone, haveRR.Name) {
+		return false
+	}
+	if wantRR.Data == "" {
+		return true
+	}
+
+	return RecordKey(zone, want) == RecordKey(zone, have)
+}
+
+// SetMXRecords replaces the MX records at the name with the given ones using a
+// single PUT to GoDaddy's type and name endpoint, so that the mail exchangers
+// of a name are configured all at once. The name may be relative or fully
+// qualified; the names of the given records are ignored. It returns the
+// records as written to GoDaddy. An empty slice deletes the MX records of the
+// name.
+//
+// Every target must be a valid hostname, and no target may be given twice.
+// The only exception is a null MX (RFC 7505), with target "." and preference
+// 0, stating that the name accepts no mail; it must be the only MX record.
+func (p *Provider) SetMXRecords(ctx context.Context, zone, name string, records []libdns.MX) ([]libdns.Record, error) {
+	name = getRecordName(zone, name)
+	if len(records) == 0 {
+		_, err := p.deleteScoped(ctx, zone, "MX", name)
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var grs []godaddyRecord
+	for _, mx := range records {
+		if mx.Target == "." {
+			if mx.Preference != 0 || len(records) > 1 {
+				return nil, fmt.Errorf("MX record %s: a null MX must have preference 0 and be the only MX record", name)
+			}
+		} else {
+			target := strings.ToLower(strings.TrimSuffix(mx.Target, "."))
+			if err := validateName(target); err != nil || target == "@" {
+				return nil, fmt.Errorf("MX record %s: invalid target %q", name, mx.Target)
+			}
+			if seen[target] {
+				return nil, fmt.Errorf("MX record %s: target %s is given twice", name, mx.Target)
+			}
+			seen[target] = true
+		}
+
+		mx.Name = name
+		gr, err := p.convertRecord(mx, zone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert record: %w", err)
+		}
+		grs = append(grs, gr)
+	}
+
+	if err := p.putRecords(ctx, zone, "MX", name, grs); err != nil {
+		return nil, fmt.Errorf("failed to set records %s.%s (MX): %w", name, getDomain(zone), err)
+	}
+	return convertToLibdnsRecords(grs), nil
+}
+
+// UpdateTTL changes the TTL of all the records of the given type and name,
+// keeping their data. The records are listed using GoDaddy's scoped endpoint
+// and written back with the new TTL in a single PUT. The name may be relative
+// or fully qualified. The TTL is subject to the same defaults and limits as
+// the TTL of any record written; zero selects the default TTL of the type.
+//
+// For SRV, the name may include the service and protocol, e.g. "_sip._tcp",
+// to only change the TTL of those records; the SRV records of other services
+// at the name are written back unchanged. Without them, the TTL of all SRV
+// records at the name is changed.
+//
+// It returns the records as written, or an error wrapping ErrRecordNotFound if
+// the zone has no records of that type and name. No request is made to write
+// the records if their TTL is already the requested one.
+func (p *Provider) UpdateTTL(ctx context.Context, zone, recType, name string, ttl time.Duration) ([]libdns.Record, error) {
+	recType = strings.ToUpper(recType)
+	name = getRecordName(zone, name)
+	// SRV records are stored under the name without their service and protocol
+	stored := name
+	if recType == "SRV" {
+		stored = srvBaseName(name)
+	}
+
+	current, err := p.listRecords(ctx, zone, recType, stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current records: %w", err)
+	}
+
+	var updated []int
+	for i, gr := range current {
+		if stored == name || strings.EqualFold(getRecordName(zone, convertToLibdnsRecord(gr).RR().Name), name) {
+			updated = append(updated, i)
+		}
+	}
+	if len(updated) == 0 {
+		return nil, fmt.Errorf("failed to update TTL of %s.%s (%s): %w",
+			name, getDomain(zone), recType, ErrRecordNotFound)
+	}
+	if isApexNS(zone, current[0]) {
+		return nil, fmt.Errorf("NS record %s: %w; change them in the nameserver settings of %s",
+			name, ErrApexNS, getDomain(zone))
+	}
+
+	changed := false
+	var records []godaddyRecord
+	for _, i := range updated {
+		seconds, err := p.recordTTL(current[i], ttl)
+		if err != nil {
+			return nil, err
+		}
+		changed = changed || current[i].TTL != seconds
+		current[i].TTL = seconds
+		records = append(records, current[i])
+	}
+
+	if changed {
+		if err := p.putRecords(ctx, zone, recType, stored, current); err != nil {
+			return nil, fmt.Errorf("failed to update TTL of %s.%s (%s): %w",
+				name, getDomain(zone), recType, err)
+		}
+	}
+	return convertToLibdnsRecords(records), nil
+}
+
+// ReplaceAllRecords replaces the entire record set of the zone with the given
+// records in a single request. It returns the records as written to GoDaddy,
+// i.e. with the TTL limits applied.
+//
+// WARNING: any record in the zone that is not included in the input is
+// removed, including records created outside of this provider. For a zone
+// delegated below its registered domain, the records of the domain outside
+// of the zone are kept.
+//
+// The NS records at the apex of a registered domain, its nameservers, are
+// always kept as they are, see ErrApexNS. They may be included in the input,
+// e.g. when passing the records returned by GetRecords, as long as they match
+// the current nameservers; otherwise ErrApexNS is returned.
+func (p *Provider) ReplaceAllRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	var grs []godaddyRecord
+	var nameservers []libdns.Record
+	for _, record := range records {
+		gr, err := p.convertRecord(record, zone)
+		if errors.Is(err, ErrApexNS) {
+			// Checked against the current nameservers below
+			nameservers = append(nameservers, record)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert record: %w", err)
+		}
+		grs = append(grs, gr)
+	}
+	if err := validateCNAMEConflicts(grs); err != nil {
+		return nil, err
+	}
+	if err := p.checkRecordLimits(zone, nil, grs, true); err != nil {
+		return nil, err
+	}
+
+	var kept []godaddyRecord
+	if getZonePrefix(zone) == "" {
+		var err error
+		kept, err = p.listRecords(ctx, zone, "NS", "@")
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the nameservers of %s: %w", getDomain(zone), err)
+		}
+	}
+	for _, record := range nameservers {
+		current := slices.ContainsFunc(kept, func(gr godaddyRecord) bool {
+			return RecordKey(zone, convertToLibdnsRecord(gr)) == RecordKey(zone, record)
+		})
+		if !current {
+			return nil, fmt.Errorf("NS record %s: %w; change them in the nameserver settings of %s",
+				record.RR().Name, ErrApexNS, getDomain(zone))
+		}
+	}
+
+	grs = append(kept, grs...)
+	if err := p.replaceZone(ctx, zone, grs); err != nil {
+		return nil, err
+	}
+
+	return convertToLibdnsRecords(grs), nil
+}
+
+// replaceZone replaces all the records of the zone with the given records,
+// which are relative to the zone. For a registered domain they must include
+// its apex NS records, which would otherwise be removed.
+func (p *Provider) replaceZone(ctx context.Context, zone string, grs []godaddyRecord) error {
+	// GoDaddy replaces the records of the whole registered domain, so for a
+	// delegated zone the records outside of it are sent back unchanged
+	body := toDomainRecords(zone, grs)
+	if getZonePrefix(zone) != "" {
+		domainRecords, err := p.listRecords(ctx, getDomain(zone), "", "")
+		if err != nil {
+			return fmt.Errorf("failed to fetch records of %s: %w", getDomain(zone), err)
+		}
+		var outside []godaddyRecord
+		for _, gr := range domainRecords {
+			if _, ok := fromDomainName(zone, gr.Name); !ok {
+				outside = append(outside, gr)
+			}
+		}
+		body = append(outside, body...)
+	}
+
+	// GoDaddy expects an array, even when the zone is emptied
+	if body == nil {
+		body = []godaddyRecord{}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record data: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/domains/%s/records", p.getApiHost(), getDomain(zone))
+
+	resp, bodyBytes, err := p.doZoneRequest(ctx, zone, http.MethodPut, url, data)
+	if err != nil {
+		return err
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return fmt.Errorf("failed to replace records of %s: %w",
+			getDomain(zone), newZoneError(resp, bodyBytes, zone, body...))
+	}
+
+	return nil
+}
+
+// planDeletion finds the current records matching the records to delete. For
+// every type and name affected it returns a group holding the matched records
+// in Deleted and the records that must be kept in Records; an empty Records
+// means the whole type and name can be deleted.
+func planDeletion(zone string, current []godaddyRecord, records []libdns.Record) []*recordGroup {
+	var groups []*recordGroup
+	index := make(map[string]*recordGroup)
+
+	// Find records that actually exist in the zone
+	for _, gr := range current {
+		if isApexNS(zone, gr) {
+			continue
+		}
+		for _, record := range records {
+			if recordMatches(zone, record, convertToLibdnsRecord(gr)) {
+				key := gr.Type + "/" + getRecordName(zone, gr.Name)
+				group, ok := index[key]
+				if !ok {
+					group = &recordGroup{Type: gr.Type, Name: getRecordName(zone, gr.Name)}
+					index[key] = group
+					groups = append(groups, group)
+				}
+				group.Deleted = append(group.Deleted, gr)
+				break
+			}
+		}
+	}
+
+	// Collect the records sharing an affected type and name that must survive
+	for _, gr := range current {
+		group, ok := index[gr.Type+"/"+getRecordName(zone, gr.Name)]
+		if !ok {
+			continue
+		}
+		matched := false
+		for _, record := range records {
+			if recordMatches(zone, record, convertToLibdnsRecord(gr)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			group.Records = append(group.Records, gr)
+		}
+	}
+
+	return groups
+}
+
+// DeleteRecords deletes the records from the zone.
+//
+// A record is only deleted if its type, name and data match one of the given
+// records. If a given record has no data, all records of that type and name
+// are deleted.
+//
+// Since GoDaddy can only delete all records of a type and name at once, other
+// records sharing the type and name of a deleted record are written back with
+// a single PUT instead. If no records remain, the type and name is deleted.
+//
+// When all the given records share a type and name, only the records of that
+// type and name are listed, using GoDaddy's scoped endpoint; otherwise the
+// whole zone is listed to find the records to delete.
+//
+// With BulkDeleteThreshold set, a deletion affecting more types and names than
+// the threshold is instead applied by replacing the whole zone with the
+// records that remain, in a single request.
+//
+// The NS records at the apex of a registered domain, its nameservers, are
+// never deleted, see ErrApexNS.
+//
+// If the context is done before all records are deleted, the records deleted
+// so far are returned along with the context error.
+func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	var currentRecords []godaddyRecord
+	var err error
+	if recType, name, ok := sharedTypeName(zone, records); ok {
+		currentRecords, err = p.listRecords(ctx, zone, recType, name)
+	} else {
+		currentRecords, err = p.getRecords(ctx, zone)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current records: %w", err)
+	}
+
+	groups := planDeletion(zone, currentRecords, records)
+	if p.BulkDeleteThreshold > 0 && len(groups) > p.BulkDeleteThreshold {
+		// The whole zone is replaced with the remaining records, so they must
+		// not come from the cache, or records created since would be erased
+		if p.CacheTTL > 0 {
+			currentRecords, err = p.listRecords(ctx, zone, "", "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to get current records: %w", err)
+			}
+			groups = planDeletion(zone, currentRecords, records)
+		}
+		return p.bulkDelete(ctx, zone, currentRecords, groups)
+	}
+
+	return p.deleteGroups(ctx, zone, groups)
+}
+
+// bulkDelete applies the planned deletions by replacing the whole zone with
+// the current records that are not deleted
+func (p *Provider) bulkDelete(ctx context.Context, zone string, current []godaddyRecord, groups []*recordGroup) ([]libdns.Record, error) {
+	deleted := make(map[string]bool)
+	var deletedRecords []libdns.Record
+	for _, group := range groups {
+		for _, gr := range group.Deleted {
+			deleted[RecordKey(zone, convertToLibdnsRecord(gr))] = true
+		}
+		deletedRecords = append(deletedRecords, convertToLibdnsRecords(group.Deleted)...)
+	}
+
+	remaining := []godaddyRecord{}
+	for _, gr := range current {
+		if !deleted[RecordKey(zone, convertToLibdnsRecord(gr))] {
+			remaining = append(remaining, gr)
+		}
+	}
+
+	if err := p.replaceZone(ctx, zone, remaining); err != nil {
+		return nil, fmt.Errorf("failed to delete records: %w", err)
+	}
+
+	return deletedRecords, nil
+}
+
+// sharedTypeName returns the type and relative name shared by all records,
+// reporting false if there are none or they differ. The name is the one GoDaddy
+// stores the records under, i.e. without the service and protocol of SRV records.
+func sharedTypeName(zone string, records []libdns.Record) (string, string, bool) {
+	var recType, name string
+	for i, record := range records {
+		rr := record.RR()
+		if rr.Type == "" {
+			return "", "", false
+		}
+		recName := getRecordName(zone, rr.Name)
+		if rr.Type == "SRV" {
+			recName = srvBaseName(recName)
+		}
+		if i == 0 {
+			recType, name = rr.Type, recName
+		} else if rr.Type != recType || recName != name {
+			return "", "", false
+		}
+	}
+	return recType, name, recType != ""
+}
+
+// srvBaseName strips the service and protocol labels from the relative name of
+// an SRV record, e.g. "_sip._tcp.sub" becomes "sub" and "_sip._tcp" becomes "@"
+func srvBaseName(name string) string {
+	labels := strings.SplitN(name, ".", 3)
+	if len(labels) < 2 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+		return name
+	}
+	if len(labels) == 2 {
+		return "@"
+	}
+	return labels[2]
+}
+
+// DeleteRecordsByType deletes all records of the given type from the zone and
+// returns them. Only the records of that type are listed, using GoDaddy's
+// scoped endpoint, and each of their names is then deleted with one request.
+// As with DeleteRecords, the NS records at the apex of a registered domain are
+// kept.
+//
+// If the context is done before all records are deleted, the records deleted
+// so far are returned along with the context error.
+func (p *Provider) DeleteRecordsByType(ctx context.Context, zone, recType string) ([]libdns.Record, error) {
+	return p.deleteScoped(ctx, zone, strings.ToUpper(recType), "")
+}
+
+// DeleteRecordsByTypeName deletes all records of the given type and name from
+// the zone with a single request and returns them. The name may be relative or
+// fully qualified. For SRV, the name may include the service and protocol,
+// e.g. "_sip._tcp", to only delete those records, writing back the SRV records
+// of other services at the name; without them, all SRV records at the name
+// are deleted.
+func (p *Provider) DeleteRecordsByTypeName(ctx context.Context, zone, recType, name string) ([]libdns.Record, error) {
+	return p.deleteScoped(ctx, zone, strings.ToUpper(recType), getRecordName(zone, name))
+}
+
+// deleteScoped deletes all records of a type, and of a name if not empty
+func (p *Provider) deleteScoped(ctx context.Context, zone, recType, name string) ([]libdns.Record, error) {
+	// SRV records are stored under the name without their service and protocol
+	stored := name
+	if recType == "SRV" {
+		stored = srvBaseName(name)
+	}
+
+	currentRecords, err := p.listRecords(ctx, zone, recType, stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current records: %w", err)
+	}
+
+	var groups []*recordGroup
+	index := make(map[string]*recordGroup)
+	for _, gr := range currentRecords {
+		if isApexNS(zone, gr) {
+			continue
+		}
+		group, ok := index[gr.Name]
+		if !ok {
+			group = &recordGroup{Type: recType, Name: gr.Name}
+			index[gr.Name] = group
+			groups = append(groups, group)
+		}
+		// The SRV records of other services are written back
+		if stored != name && !strings.EqualFold(getRecordName(zone, convertToLibdnsRecord(gr).RR().Name), name) {
+			group.Records = append(group.Records, gr)
+		} else {
+			group.Deleted = append(group.Deleted, gr)
+		}
+	}
+	groups = slices.DeleteFunc(groups, func(group *recordGroup) bool {
+		return len(group.Deleted) == 0
+	})
+
+	return p.deleteGroups(ctx, zone, groups)
+}
+
+// deleteGroups applies the planned deletions and returns the deleted records.
+// If the context is done before all groups are handled, the records deleted so
+// far are returned along with the context error.
+func (p *Provider) deleteGroups(ctx context.Context, zone string, groups []*recordGroup) ([]libdns.Record, error) {
+	done, err := p.runConcurrently(ctx, len(groups), func(ctx context.Context, i int) error {
+		return p.deleteGroup(ctx, zone, groups[i])
+	})
+
+	var deletedRecords []libdns.Record
+	for i, group := range groups {
+		if done[i] {
+			deletedRecords = append(deletedRecords, convertToLibdnsRecords(group.Deleted)...)
 		}
 	}
 
+	if err != nil {
+		if ctx.Err() != nil {
+			return deletedRecords, err
+		}
+		return nil, err
+	}
+
 	return deletedRecords, nil
 }
 
+// deleteGroup removes the deleted records of a group from the zone, writing
+// back the records that must be kept or deleting the type and name entirely
+func (p *Provider) deleteGroup(ctx context.Context, zone string, group *recordGroup) error {
+	if len(group.Records) > 0 {
+		// Write back the records that must be kept
+		if err := p.putRecords(ctx, zone, group.Type, group.Name, group.Records); err != nil {
+			return fmt.Errorf("failed to delete records %s.%s (%s): %w",
+				group.Name, getDomain(zone), group.Type, err)
+		}
+		return nil
+	}
+
+	url := fmt.Sprintf("%s/v1/domains/%s/records/%s/%s",
+		p.getApiHost(), getDomain(zone), group.Type, toDomainName(zone, group.Name))
+
+	resp, bodyBytes, err := p.doZoneRequest(ctx, zone, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete records %s.%s (%s): %w",
+			group.Name, getDomain(zone), group.Type, err)
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		err := newRecordsError(resp, bodyBytes, zone)
+		// Unless the domain is missing, a 404 means the records are gone
+		// already, e.g. deleted concurrently
+		if resp.StatusCode == http.StatusNotFound && !errors.Is(err, ErrZoneNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete records %s.%s (%s): %w",
+			group.Name, getDomain(zone), group.Type, err)
+	}
+
+	return nil
+}
+
 // Interface guards
 var (
 	_ libdns.RecordGetter   = (*Provider)(nil)
 	_ libdns.RecordAppender = (*Provider)(nil)
 	_ libdns.RecordSetter   = (*Provider)(nil)
 	_ libdns.RecordDeleter  = (*Provider)(nil)
+	_ libdns.ZoneLister     = (*Provider)(nil)
 )
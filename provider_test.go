@@ -1,7 +1,19 @@
 package godaddy
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"net/netip"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,6 +41,40 @@ func TestProviderConfiguration(t *testing.T) {
 			},
 			expectedURL: "https://api.ote-godaddy.com",
 		},
+		{
+			name: "Custom API endpoint",
+			provider: Provider{
+				APIToken:    "test:secret",
+				APIEndpoint: "http://127.0.0.1:8080/",
+			},
+			expectedURL: "http://127.0.0.1:8080",
+		},
+		{
+			name: "OTE environment",
+			provider: Provider{
+				APIToken:    "test:secret",
+				Environment: EnvironmentOTE,
+			},
+			expectedURL: "https://api.ote-godaddy.com",
+		},
+		{
+			name: "Environment overrides UseOTE",
+			provider: Provider{
+				APIToken:    "test:secret",
+				Environment: "Production",
+				UseOTE:      true,
+			},
+			expectedURL: "https://api.godaddy.com",
+		},
+		{
+			name: "Custom API endpoint overrides OTE",
+			provider: Provider{
+				APIToken:    "test:secret",
+				UseOTE:      true,
+				APIEndpoint: "http://127.0.0.1:8080",
+			},
+			expectedURL: "http://127.0.0.1:8080",
+		},
 	}
 
 	for _, tt := range tests {
@@ -41,6 +87,15 @@ func TestProviderConfiguration(t *testing.T) {
 	}
 }
 
+func TestUnknownEnvironment(t *testing.T) {
+	provider := Provider{APIToken: "test:secret", Environment: "staging"}
+
+	_, err := provider.GetRecords(context.Background(), "example.com.")
+	if err == nil || !strings.Contains(err.Error(), `unknown GoDaddy environment "staging"`) {
+		t.Errorf("Expected an unknown environment error, got %v", err)
+	}
+}
+
 func TestHTTPClientConfiguration(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -74,6 +129,72 @@ func TestHTTPClientConfiguration(t *testing.T) {
 	}
 }
 
+func TestCustomHTTPClient(t *testing.T) {
+	transport := &http.Transport{}
+
+	t.Run("Client with timeout is used as is", func(t *testing.T) {
+		custom := &http.Client{Transport: transport, Timeout: 10 * time.Second}
+		provider := Provider{APIToken: "test:secret", HTTPClient: custom, HTTPTimeout: time.Minute}
+
+		if client := provider.getHTTPClient(); client != custom {
+			t.Errorf("Expected the injected client to be returned")
+		}
+	})
+
+	t.Run("Client without timeout gets HTTPTimeout", func(t *testing.T) {
+		custom := &http.Client{Transport: transport}
+		provider := Provider{APIToken: "test:secret", HTTPClient: custom, HTTPTimeout: time.Minute}
+
+		client := provider.getHTTPClient()
+		if client.Timeout != time.Minute {
+			t.Errorf("HTTP client timeout = %v; expected %v", client.Timeout, time.Minute)
+		}
+		if client.Transport != transport {
+			t.Errorf("Expected the injected transport to be reused")
+		}
+		if custom.Timeout != 0 {
+			t.Errorf("Injected client was modified")
+		}
+	})
+}
+
+func TestProxyConfiguration(t *testing.T) {
+	t.Run("Proxy sets the transport proxy", func(t *testing.T) {
+		provider := Provider{APIToken: "test:secret", Proxy: "http://proxy.internal:3128"}
+
+		transport, ok := provider.getHTTPClient().Transport.(*http.Transport)
+		if !ok || transport.Proxy == nil {
+			t.Fatalf("Expected a transport with a proxy, got %#v", provider.getHTTPClient().Transport)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "https://api.godaddy.com/v1/domains", nil)
+		proxy, err := transport.Proxy(req)
+		if err != nil || proxy == nil || proxy.String() != "http://proxy.internal:3128" {
+			t.Errorf("Proxy mismatch: expected http://proxy.internal:3128, got %v (%v)", proxy, err)
+		}
+		if other := provider.getHTTPClient().Transport; other != transport {
+			t.Errorf("Expected the transport to be reused across requests")
+		}
+	})
+
+	t.Run("Proxy is ignored with an injected client", func(t *testing.T) {
+		custom := &http.Client{Timeout: time.Second}
+		provider := Provider{APIToken: "test:secret", HTTPClient: custom, Proxy: "http://proxy.internal:3128"}
+
+		if client := provider.getHTTPClient(); client != custom {
+			t.Errorf("Expected the injected client to be returned")
+		}
+	})
+
+	t.Run("Invalid proxy URL", func(t *testing.T) {
+		provider := Provider{APIToken: "test:secret", Proxy: "proxy.internal:3128"}
+
+		_, err := provider.GetRecords(context.Background(), "example.com.")
+		if err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+			t.Errorf("Expected an invalid proxy URL error, got %v", err)
+		}
+	})
+}
+
 func TestConvertToLibdnsRecord(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -142,14 +263,99 @@ func TestConvertToLibdnsRecord(t *testing.T) {
 			input: godaddyRecord{
 				Type: "MX",
 				Name: "@",
-				Data: "invalid-mx-format",
+				Data: "10 mail.example.com extra",
 				TTL:  3600,
 			},
 			expected: libdns.RR{
 				Name: "@",
 				TTL:  time.Hour,
 				Type: "MX",
-				Data: "invalid-mx-format",
+				Data: "10 mail.example.com extra",
+			},
+		},
+		{
+			name: "SRV Record",
+			input: godaddyRecord{
+				Type:     "SRV",
+				Name:     "@",
+				Data:     "sip.example.com",
+				TTL:      3600,
+				Priority: 10,
+				Weight:   5,
+				Port:     5060,
+				Service:  "_sip",
+				Protocol: "_tcp",
+			},
+			expected: libdns.SRV{
+				Service:   "sip",
+				Transport: "tcp",
+				Name:      "@",
+				TTL:       time.Hour,
+				Priority:  10,
+				Weight:    5,
+				Port:      5060,
+				Target:    "sip.example.com",
+			},
+		},
+		{
+			name: "CAA Record",
+			input: godaddyRecord{
+				Type: "CAA",
+				Name: "@",
+				Data: `0 issue "letsencrypt.org"`,
+				TTL:  3600,
+			},
+			expected: libdns.CAA{
+				Name:  "@",
+				TTL:   time.Hour,
+				Flags: 0,
+				Tag:   "issue",
+				Value: "letsencrypt.org",
+			},
+		},
+		{
+			name: "Malformed CAA Record - fallback to RR",
+			input: godaddyRecord{
+				Type: "CAA",
+				Name: "@",
+				Data: "issue letsencrypt.org",
+				TTL:  3600,
+			},
+			expected: libdns.RR{
+				Name: "@",
+				TTL:  time.Hour,
+				Type: "CAA",
+				Data: "issue letsencrypt.org",
+			},
+		},
+		{
+			name: "PTR Record",
+			input: godaddyRecord{
+				Type: "PTR",
+				Name: "1",
+				Data: "host.example.com",
+				TTL:  3600,
+			},
+			expected: libdns.RR{
+				Name: "1",
+				TTL:  time.Hour,
+				Type: "PTR",
+				Data: "host.example.com",
+			},
+		},
+		{
+			name: "SRV Record missing fields - fallback to RR",
+			input: godaddyRecord{
+				Type: "SRV",
+				Name: "@",
+				Data: "sip.example.com",
+				TTL:  3600,
+			},
+			expected: libdns.RR{
+				Name: "@",
+				TTL:  time.Hour,
+				Type: "SRV",
+				Data: "sip.example.com",
 			},
 		},
 	}
@@ -178,6 +384,157 @@ func TestConvertToLibdnsRecord(t *testing.T) {
 	}
 }
 
+func TestConvertToLibdnsRecordMX(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected libdns.Record
+	}{
+		{
+			name:     "Embedded priority",
+			json:     `{"type":"MX","name":"@","data":"10 mail.example.com","ttl":3600}`,
+			expected: libdns.MX{Name: "@", TTL: time.Hour, Preference: 10, Target: "mail.example.com"},
+		},
+		{
+			name:     "Embedded priority with extra whitespace",
+			json:     `{"type":"MX","name":"@","data":" 10   mail.example.com ","ttl":3600}`,
+			expected: libdns.MX{Name: "@", TTL: time.Hour, Preference: 10, Target: "mail.example.com"},
+		},
+		{
+			name:     "Priority field",
+			json:     `{"type":"MX","name":"@","data":"mail.example.com","priority":20,"ttl":3600}`,
+			expected: libdns.MX{Name: "@", TTL: time.Hour, Preference: 20, Target: "mail.example.com"},
+		},
+		{
+			name:     "Priority field wins over embedded priority",
+			json:     `{"type":"MX","name":"@","data":"10 mail.example.com","priority":20,"ttl":3600}`,
+			expected: libdns.MX{Name: "@", TTL: time.Hour, Preference: 20, Target: "mail.example.com"},
+		},
+		{
+			name:     "Zero priority field",
+			json:     `{"type":"MX","name":"@","data":"mail.example.com","priority":0,"ttl":3600}`,
+			expected: libdns.MX{Name: "@", TTL: time.Hour, Preference: 0, Target: "mail.example.com"},
+		},
+		{
+			name:     "Missing priority",
+			json:     `{"type":"MX","name":"@","data":"mail.example.com","ttl":3600}`,
+			expected: libdns.MX{Name: "@", TTL: time.Hour, Preference: 0, Target: "mail.example.com"},
+		},
+		{
+			name:     "Priority field out of range",
+			json:     `{"type":"MX","name":"@","data":"mail.example.com","priority":70000,"ttl":3600}`,
+			expected: libdns.RR{Name: "@", TTL: time.Hour, Type: "MX", Data: "mail.example.com"},
+		},
+		{
+			name:     "Invalid priority",
+			json:     `{"type":"MX","name":"@","data":"high mail.example.com","ttl":3600}`,
+			expected: libdns.RR{Name: "@", TTL: time.Hour, Type: "MX", Data: "high mail.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gr godaddyRecord
+			if err := json.Unmarshal([]byte(tt.json), &gr); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result := convertToLibdnsRecord(gr); result != tt.expected {
+				t.Errorf("Record mismatch: expected %#v, got %#v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSVCBRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected libdns.Record
+	}{
+		{
+			name: "HTTPS with alpn and ipv4hint",
+			json: `{"type":"HTTPS","name":"@","data":"1 . ipv4hint=192.0.2.1,192.0.2.2 alpn=h2,h3","ttl":3600}`,
+			expected: libdns.ServiceBinding{
+				Scheme:   "https",
+				Name:     "@",
+				TTL:      time.Hour,
+				Priority: 1,
+				Target:   ".",
+				Params: libdns.SvcParams{
+					"alpn":     {"h2", "h3"},
+					"ipv4hint": {"192.0.2.1", "192.0.2.2"},
+				},
+			},
+		},
+		{
+			name: "SVCB with port",
+			json: `{"type":"SVCB","name":"_8443._foo.api","data":"2 svc.example.com. port=8443","ttl":3600}`,
+			expected: libdns.ServiceBinding{
+				Scheme:        "foo",
+				URLSchemePort: 8443,
+				Name:          "api",
+				TTL:           time.Hour,
+				Priority:      2,
+				Target:        "svc.example.com.",
+				Params:        libdns.SvcParams{"port": {"8443"}},
+			},
+		},
+		{
+			name:     "Unknown key",
+			json:     `{"type":"HTTPS","name":"@","data":"1 . bogus=1","ttl":3600}`,
+			expected: libdns.RR{Name: "@", TTL: time.Hour, Type: "HTTPS", Data: "1 . bogus=1"},
+		},
+		{
+			name:     "Invalid hint",
+			json:     `{"type":"HTTPS","name":"@","data":"1 . ipv4hint=2001:db8::1","ttl":3600}`,
+			expected: libdns.RR{Name: "@", TTL: time.Hour, Type: "HTTPS", Data: "1 . ipv4hint=2001:db8::1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gr godaddyRecord
+			if err := json.Unmarshal([]byte(tt.json), &gr); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result := convertToLibdnsRecord(gr); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Record mismatch: expected %#v, got %#v", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		record := libdns.ServiceBinding{
+			Scheme:   "https",
+			Name:     "www",
+			TTL:      time.Hour,
+			Priority: 1,
+			Target:   ".",
+			Params: libdns.SvcParams{
+				"ipv4hint": {"192.0.2.1"},
+				"alpn":     {"h2", "h3"},
+			},
+		}
+		result, err := convertFromLibdnsRecord(record, "example.com.")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expected := "1 . alpn=h2,h3 ipv4hint=192.0.2.1"; result.Data != expected {
+			t.Errorf("Data mismatch: expected %q, got %q", expected, result.Data)
+		}
+		if result.Type != "HTTPS" || result.Name != "www" {
+			t.Errorf("Record mismatch: expected HTTPS www, got %s %s", result.Type, result.Name)
+		}
+	})
+
+	t.Run("Write malformed", func(t *testing.T) {
+		record := libdns.RR{Name: "www", TTL: time.Hour, Type: "HTTPS", Data: "1 . bogus=1"}
+		if _, err := convertFromLibdnsRecord(record, "example.com."); err == nil {
+			t.Error("Expected error for unknown SvcParam key")
+		}
+	})
+}
+
 func TestConvertFromLibdnsRecord(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -230,6 +587,23 @@ func TestConvertFromLibdnsRecord(t *testing.T) {
 				TTL:  900, // Original TTL preserved
 			},
 		},
+		{
+			name: "MX Record",
+			input: libdns.MX{
+				Name:       "@",
+				TTL:        time.Hour,
+				Preference: 10,
+				Target:     "mail.example.com.",
+			},
+			zone: "example.com.",
+			expected: godaddyRecord{
+				Type:     "MX",
+				Name:     "@",
+				Data:     "mail.example.com.",
+				TTL:      3600,
+				Priority: 10,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -251,10 +625,83 @@ func TestConvertFromLibdnsRecord(t *testing.T) {
 			if result.TTL != tt.expected.TTL {
 				t.Errorf("TTL mismatch: expected %d, got %d", tt.expected.TTL, result.TTL)
 			}
+			if result.Priority != tt.expected.Priority {
+				t.Errorf("Priority mismatch: expected %d, got %d", tt.expected.Priority, result.Priority)
+			}
+		})
+	}
+}
+
+func TestMarshalMXPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    libdns.MX
+		expected string
+	}{
+		{
+			name:     "Preference",
+			input:    libdns.MX{Name: "@", TTL: time.Hour, Preference: 10, Target: "mail.example.com"},
+			expected: `{"type":"MX","name":"@","data":"mail.example.com","ttl":3600,"priority":10}`,
+		},
+		{
+			name:     "Zero preference",
+			input:    libdns.MX{Name: "@", TTL: time.Hour, Preference: 0, Target: "mail.example.com"},
+			expected: `{"data":"mail.example.com","name":"@","priority":0,"ttl":3600,"type":"MX"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gr, err := convertFromLibdnsRecord(tt.input, "example.com.")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			data, err := json.Marshal(gr)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("JSON mismatch: expected %s, got %s", tt.expected, data)
+			}
+
+			// The record is read back as written
+			var read godaddyRecord
+			if err := json.Unmarshal(data, &read); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if key := RecordKey("example.com.", convertToLibdnsRecord(read)); key != RecordKey("example.com.", tt.input) {
+				t.Errorf("Key mismatch: expected %s, got %s", RecordKey("example.com.", tt.input), key)
+			}
 		})
 	}
 }
 
+func TestMarshalSRVFields(t *testing.T) {
+	record := libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", TTL: time.Hour, Priority: 0, Weight: 0, Port: 5060, Target: "sip.example.com"}
+	gr, err := convertFromLibdnsRecord(record, "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := json.Marshal(gr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"data":"sip.example.com","name":"@","port":5060,"priority":0,"protocol":"_tcp","service":"_sip","ttl":3600,"type":"SRV","weight":0}`
+	if string(data) != expected {
+		t.Errorf("JSON mismatch: expected %s, got %s", expected, data)
+	}
+
+	// The record is read back as written
+	var read godaddyRecord
+	if err := json.Unmarshal(data, &read); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if key := RecordKey("example.com.", convertToLibdnsRecord(read)); key != RecordKey("example.com.", record) {
+		t.Errorf("Key mismatch: expected %s, got %s", RecordKey("example.com.", record), key)
+	}
+}
+
 func TestGetRecordName(t *testing.T) {
 	tests := []struct {
 		zone     string
@@ -266,6 +713,26 @@ func TestGetRecordName(t *testing.T) {
 		{"example.com.", "sub.example.com.", "sub"},
 		{"example.com.", "test", "test"},
 		{"example.com.", "_acme-challenge.sub.example.com.", "_acme-challenge.sub"},
+		{"example.com.", "example.com.", "@"},
+		{"example.com", "example.com", "@"},
+		{"example.com.", "", "@"},
+		{"example.com.", "*.example.com.", "*"},
+		{"example.com.", "*.sub.example.com.", "*.sub"},
+		{"example.com.", "*", "*"},
+		{"example.com", "www.example.com.", "www"},
+		{"example.com.", "www.example.com", "www"},
+		{"example.com", "www.example.com", "www"},
+		{"example.com", "example.com.", "@"},
+		{"example.com.", "example.com", "@"},
+		{"example.com", "*.sub.example.com.", "*.sub"},
+		{"example.com", "test.", "test"},
+		{"example.com.", "notexample.com.", "notexample.com"},
+		{"example.com.", "WWW.Example.COM.", "WWW"},
+		{"münchen.de.", "www.münchen.de.", "www"},
+		{"münchen.de.", "www.xn--mnchen-3ya.de.", "www"},
+		{"xn--mnchen-3ya.de.", "straße.münchen.de.", "xn--strae-oqa"},
+		{"example.com.", "_acme-challenge.bücher", "_acme-challenge.xn--bcher-kva"},
+		{"example.com.", "*.bücher.example.com.", "*.xn--bcher-kva"},
 	}
 
 	for _, tt := range tests {
@@ -275,3 +742,3630 @@ func TestGetRecordName(t *testing.T) {
 		}
 	}
 }
+
+func TestDelegatedZoneNames(t *testing.T) {
+	tests := []struct {
+		zone       string
+		domain     string
+		prefix     string
+		name       string
+		domainName string
+	}{
+		{"example.com.", "example.com", "", "www", "www"},
+		{"example.com.", "example.com", "", "@", "@"},
+		{"example.co.uk.", "example.co.uk", "", "www", "www"},
+		{"k8s.example.com.", "example.com", "k8s", "app", "app.k8s"},
+		{"k8s.example.com.", "example.com", "k8s", "@", "k8s"},
+		{"k8s.example.com", "example.com", "k8s", "*.apps", "*.apps.k8s"},
+		{"a.b.example.co.uk.", "example.co.uk", "a.b", "www", "www.a.b"},
+		{"münchen.de.", "xn--mnchen-3ya.de", "", "www", "www"},
+		{"bücher.münchen.de.", "xn--mnchen-3ya.de", "xn--bcher-kva", "www", "www.xn--bcher-kva"},
+	}
+
+	for _, tt := range tests {
+		if domain := getDomain(tt.zone); domain != tt.domain {
+			t.Errorf("getDomain(%s) = %s; expected %s", tt.zone, domain, tt.domain)
+		}
+		if prefix := getZonePrefix(tt.zone); prefix != tt.prefix {
+			t.Errorf("getZonePrefix(%s) = %s; expected %s", tt.zone, prefix, tt.prefix)
+		}
+		if domainName := toDomainName(tt.zone, tt.name); domainName != tt.domainName {
+			t.Errorf("toDomainName(%s, %s) = %s; expected %s", tt.zone, tt.name, domainName, tt.domainName)
+		}
+		if name, ok := fromDomainName(tt.zone, tt.domainName); !ok || name != tt.name {
+			t.Errorf("fromDomainName(%s, %s) = %s, %v; expected %s", tt.zone, tt.domainName, name, ok, tt.name)
+		}
+	}
+
+	// Names of the registered domain outside of the delegated zone
+	for _, name := range []string{"@", "www", "k8s2", "appk8s"} {
+		if _, ok := fromDomainName("k8s.example.com.", name); ok {
+			t.Errorf("fromDomainName(k8s.example.com., %s): expected the name to be outside of the zone", name)
+		}
+	}
+}
+
+func TestInternationalizedZone(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	if _, err := provider.GetRecords(ctx, "münchen.de."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := provider.DeleteRecordsByTypeName(ctx, "münchen.de.", "TXT", "_acme-challenge.bücher"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"GET /v1/domains/xn--mnchen-3ya.de/records",
+		"GET /v1/domains/xn--mnchen-3ya.de/records/TXT/_acme-challenge.xn--bcher-kva",
+	}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("Paths mismatch: expected %v, got %v", expected, paths)
+	}
+}
+
+func TestDelegatedZone(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "A", Name: "@", Data: "192.0.2.1", TTL: 600},
+		{Type: "A", Name: "www", Data: "192.0.2.2", TTL: 600},
+		{Type: "A", Name: "k8s", Data: "192.0.2.3", TTL: 600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+	const k8s = "k8s.example.com."
+
+	stored := func() map[string]bool {
+		keys := make(map[string]bool)
+		for _, gr := range zone.records {
+			keys[gr.Type+"/"+gr.Name+"/"+gr.Data] = true
+		}
+		return keys
+	}
+
+	_, err := provider.AppendRecords(ctx, k8s, []libdns.Record{
+		libdns.Address{Name: "app.k8s.example.com.", IP: netip.MustParseAddr("192.0.2.10")},
+		libdns.TXT{Name: "_acme-challenge.app", Text: "token"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if keys := stored(); !keys["A/app.k8s/192.0.2.10"] || !keys["TXT/_acme-challenge.app.k8s/token"] {
+		t.Errorf("Expected the records to be named relative to example.com, got %v", zone.records)
+	}
+
+	records, err := provider.GetRecords(ctx, k8s)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var names []string
+	for _, record := range records {
+		names = append(names, record.RR().Name)
+	}
+	if expected := []string{"@", "app", "_acme-challenge.app"}; !slices.Equal(names, expected) {
+		t.Errorf("Names mismatch: expected %v, got %v", expected, names)
+	}
+
+	if _, err := provider.SetRecords(ctx, k8s, []libdns.Record{
+		libdns.Address{Name: "app", IP: netip.MustParseAddr("192.0.2.11")},
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if keys := stored(); !keys["A/app.k8s/192.0.2.11"] || keys["A/app.k8s/192.0.2.10"] {
+		t.Errorf("Expected app.k8s to be replaced, got %v", zone.records)
+	}
+
+	if _, err := provider.DeleteRecords(ctx, k8s, []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge.app.k8s.example.com.", Text: "token"},
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if keys := stored(); keys["TXT/_acme-challenge.app.k8s/token"] {
+		t.Errorf("Expected the TXT record to be deleted, got %v", zone.records)
+	}
+
+	// Replacing the delegated zone keeps the records of example.com outside of it
+	if _, err := provider.ReplaceAllRecords(ctx, k8s, []libdns.Record{
+		libdns.Address{Name: "@", IP: netip.MustParseAddr("192.0.2.4")},
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]bool{
+		"A/@/192.0.2.1":   true,
+		"A/www/192.0.2.2": true,
+		"A/k8s/192.0.2.4": true,
+	}
+	if keys := stored(); !maps.Equal(keys, expected) {
+		t.Errorf("Records mismatch: expected %v, got %v", expected, keys)
+	}
+}
+
+func TestSRVRoundTrip(t *testing.T) {
+	input := godaddyRecord{
+		Type:     "SRV",
+		Name:     "@",
+		Data:     "sip.example.com",
+		TTL:      3600,
+		Priority: 10,
+		Weight:   5,
+		Port:     5060,
+		Service:  "_sip",
+		Protocol: "_tcp",
+	}
+
+	record := convertToLibdnsRecord(input)
+	if _, ok := record.(libdns.SRV); !ok {
+		t.Fatalf("Expected libdns.SRV, got %T", record)
+	}
+
+	result, err := convertFromLibdnsRecord(record, "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, input) {
+		t.Errorf("Round trip mismatch: expected %+v, got %+v", input, result)
+	}
+}
+
+func TestGroupRecords(t *testing.T) {
+	records := []libdns.Record{
+		libdns.Address{Name: "www", TTL: time.Hour, IP: netip.MustParseAddr("192.168.1.1")},
+		libdns.TXT{Name: "www", TTL: time.Hour, Text: "hello"},
+		libdns.Address{Name: "www.example.com.", TTL: time.Hour, IP: netip.MustParseAddr("192.168.1.2")},
+		libdns.Address{Name: "api", TTL: time.Hour, IP: netip.MustParseAddr("192.168.1.3")},
+	}
+
+	groups, err := (&Provider{}).groupRecords("example.com.", records)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		recType string
+		name    string
+		data    []string
+	}{
+		{"A", "www", []string{"192.168.1.1", "192.168.1.2"}},
+		{"TXT", "www", []string{"hello"}},
+		{"A", "api", []string{"192.168.1.3"}},
+	}
+
+	if len(groups) != len(expected) {
+		t.Fatalf("Expected %d groups, got %d", len(expected), len(groups))
+	}
+	for i, want := range expected {
+		group := groups[i]
+		if group.Type != want.recType || group.Name != want.name {
+			t.Errorf("Group %d: expected %s/%s, got %s/%s", i, want.recType, want.name, group.Type, group.Name)
+		}
+		if len(group.Records) != len(want.data) || len(group.Inputs) != len(want.data) {
+			t.Fatalf("Group %d: expected %d records, got %d", i, len(want.data), len(group.Records))
+		}
+		for j, data := range want.data {
+			if group.Records[j].Data != data {
+				t.Errorf("Group %d record %d: expected data %s, got %s", i, j, data, group.Records[j].Data)
+			}
+		}
+	}
+}
+
+func TestRecordMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     libdns.Record
+		have     libdns.Record
+		expected bool
+	}{
+		{
+			name:     "TXT same data",
+			want:     libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+			have:     libdns.TXT{Name: "_acme-challenge", TTL: time.Hour, Text: "token-1"},
+			expected: true,
+		},
+		{
+			name:     "TXT different data",
+			want:     libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+			have:     libdns.TXT{Name: "_acme-challenge", TTL: time.Hour, Text: "token-2"},
+			expected: false,
+		},
+		{
+			name:     "TXT without data matches any value",
+			want:     libdns.TXT{Name: "_acme-challenge.example.com."},
+			have:     libdns.TXT{Name: "_acme-challenge", TTL: time.Hour, Text: "token-2"},
+			expected: true,
+		},
+		{
+			name:     "Different type",
+			want:     libdns.TXT{Name: "www", Text: "192.168.1.1"},
+			have:     libdns.Address{Name: "www", IP: netip.MustParseAddr("192.168.1.1")},
+			expected: false,
+		},
+		{
+			name:     "Different name",
+			want:     libdns.TXT{Name: "www", Text: "hello"},
+			have:     libdns.TXT{Name: "api", Text: "hello"},
+			expected: false,
+		},
+		{
+			name:     "MX target with trailing dot",
+			want:     libdns.MX{Name: "@", Preference: 10, Target: "mail.example.com."},
+			have:     libdns.MX{Name: "@", Preference: 10, Target: "mail.example.com"},
+			expected: true,
+		},
+		{
+			name:     "MX different preference",
+			want:     libdns.MX{Name: "@", Preference: 10, Target: "mail.example.com"},
+			have:     libdns.MX{Name: "@", Preference: 20, Target: "mail.example.com"},
+			expected: false,
+		},
+		{
+			name:     "SRV same fields",
+			want:     libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com."},
+			have:     libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"},
+			expected: true,
+		},
+		{
+			name:     "SRV different port",
+			want:     libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5061, Target: "sip.example.com"},
+			have:     libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := recordMatches("example.com.", tt.want, tt.have); result != tt.expected {
+				t.Errorf("recordMatches() = %v; expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlanDeletion(t *testing.T) {
+	current := []godaddyRecord{
+		{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 600},
+		{Type: "TXT", Name: "_acme-challenge", Data: "token-2", TTL: 600},
+		{Type: "TXT", Name: "_acme-challenge", Data: "token-3", TTL: 600},
+		{Type: "TXT", Name: "other", Data: "value", TTL: 600},
+		{Type: "A", Name: "www", Data: "192.168.1.1", TTL: 3600},
+	}
+
+	t.Run("Delete middle TXT value", func(t *testing.T) {
+		groups := planDeletion("example.com.", current, []libdns.Record{
+			libdns.TXT{Name: "_acme-challenge.example.com.", Text: "token-2"},
+		})
+
+		if len(groups) != 1 {
+			t.Fatalf("Expected 1 group, got %d", len(groups))
+		}
+		group := groups[0]
+		if group.Type != "TXT" || group.Name != "_acme-challenge" {
+			t.Errorf("Expected TXT/_acme-challenge group, got %s/%s", group.Type, group.Name)
+		}
+		if len(group.Deleted) != 1 || group.Deleted[0].Data != "token-2" {
+			t.Errorf("Expected only token-2 to be deleted, got %+v", group.Deleted)
+		}
+		if len(group.Records) != 2 || group.Records[0].Data != "token-1" || group.Records[1].Data != "token-3" {
+			t.Errorf("Expected token-1 and token-3 to remain, got %+v", group.Records)
+		}
+	})
+
+	t.Run("Delete last value at name", func(t *testing.T) {
+		groups := planDeletion("example.com.", current, []libdns.Record{
+			libdns.TXT{Name: "other", Text: "value"},
+		})
+
+		if len(groups) != 1 || len(groups[0].Records) != 0 {
+			t.Fatalf("Expected an empty group so the name is cleared, got %+v", groups)
+		}
+		if len(groups[0].Deleted) != 1 || groups[0].Deleted[0].Name != "other" {
+			t.Errorf("Expected only other to be deleted, got %+v", groups[0].Deleted)
+		}
+	})
+
+	t.Run("Delete all values without data", func(t *testing.T) {
+		groups := planDeletion("example.com.", current, []libdns.Record{
+			libdns.TXT{Name: "_acme-challenge"},
+		})
+
+		if len(groups) != 1 || len(groups[0].Records) != 0 {
+			t.Fatalf("Expected an empty group so the name is cleared, got %+v", groups)
+		}
+		if len(groups[0].Deleted) != 3 {
+			t.Errorf("Expected 3 records to be deleted, got %d", len(groups[0].Deleted))
+		}
+	})
+
+	t.Run("Nonexistent record", func(t *testing.T) {
+		groups := planDeletion("example.com.", current, []libdns.Record{
+			libdns.TXT{Name: "_acme-challenge", Text: "token-4"},
+		})
+
+		if len(groups) != 0 {
+			t.Errorf("Expected nothing to be deleted, got %+v", groups)
+		}
+	})
+}
+
+func TestGetRecordsWithMockServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/domains/example.com/records" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "sso-key test:secret" {
+			t.Errorf("Unexpected Authorization header: %s", auth)
+		}
+		json.NewEncoder(w).Encode([]godaddyRecord{
+			{Type: "A", Name: "www", Data: "192.168.1.1", TTL: 3600},
+			{Type: "TXT", Name: "_acme-challenge", Data: "token", TTL: 600},
+		})
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	records, err := provider.GetRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if _, ok := records[0].(libdns.Address); !ok {
+		t.Errorf("Expected libdns.Address, got %T", records[0])
+	}
+	if _, ok := records[1].(libdns.TXT); !ok {
+		t.Errorf("Expected libdns.TXT, got %T", records[1])
+	}
+}
+
+func TestGetRecordsPagination(t *testing.T) {
+	var zoneRecords []godaddyRecord
+	for i := 0; i < 7; i++ {
+		zoneRecords = append(zoneRecords, godaddyRecord{
+			Type: "TXT",
+			Name: "record" + strconv.Itoa(i),
+			Data: "value",
+			TTL:  600,
+		})
+	}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(offset+limit, len(zoneRecords))
+		page := []godaddyRecord{}
+		if offset < end {
+			page = zoneRecords[offset:end]
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, PageSize: 3}
+	records, err := provider.GetRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != len(zoneRecords) {
+		t.Fatalf("Expected %d records, got %d", len(zoneRecords), len(records))
+	}
+	for i, record := range records {
+		if name := record.RR().Name; name != zoneRecords[i].Name {
+			t.Errorf("Record %d: expected name %s, got %s", i, zoneRecords[i].Name, name)
+		}
+	}
+
+	expected := []string{"offset=0&limit=3", "offset=3&limit=3", "offset=6&limit=3"}
+	if len(requests) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d: %v", len(expected), len(requests), requests)
+	}
+	for i, query := range expected {
+		if requests[i] != query {
+			t.Errorf("Request %d: expected query %s, got %s", i, query, requests[i])
+		}
+	}
+}
+
+func TestParseCAA(t *testing.T) {
+	tests := []struct {
+		data  string
+		flags uint8
+		tag   string
+		value string
+		ok    bool
+	}{
+		{`0 issue "letsencrypt.org"`, 0, "issue", "letsencrypt.org", true},
+		{`128 issuewild "letsencrypt.org; validationmethods=dns-01"`, 128, "issuewild", "letsencrypt.org; validationmethods=dns-01", true},
+		{`0 iodef "mailto:security@example.com"`, 0, "iodef", "mailto:security@example.com", true},
+		{`0 issue letsencrypt.org`, 0, "issue", "letsencrypt.org", true},
+		{`0 issue "say \"hi\" \\ bye"`, 0, "issue", `say "hi" \ bye`, true},
+		{`0  issue   "letsencrypt.org"`, 0, "issue", "letsencrypt.org", true},
+		{`0 issue "unterminated`, 0, "", "", false},
+		{`256 issue "letsencrypt.org"`, 0, "", "", false},
+		{`issue "letsencrypt.org"`, 0, "", "", false},
+		{`0`, 0, "", "", false},
+	}
+
+	for _, tt := range tests {
+		flags, tag, value, ok := parseCAA(tt.data)
+		if ok != tt.ok || flags != tt.flags || tag != tt.tag || value != tt.value {
+			t.Errorf("parseCAA(%s) = (%d, %s, %s, %v); expected (%d, %s, %s, %v)",
+				tt.data, flags, tag, value, ok, tt.flags, tt.tag, tt.value, tt.ok)
+		}
+	}
+}
+
+func TestCAARoundTrip(t *testing.T) {
+	inputs := []godaddyRecord{
+		{Type: "CAA", Name: "@", Data: `0 issue "letsencrypt.org"`, TTL: 3600},
+		{Type: "CAA", Name: "@", Data: `0 issue "say \"hi\" \\ bye"`, TTL: 3600},
+	}
+
+	for _, input := range inputs {
+		record := convertToLibdnsRecord(input)
+		if _, ok := record.(libdns.CAA); !ok {
+			t.Fatalf("Expected libdns.CAA, got %T", record)
+		}
+
+		result, err := convertFromLibdnsRecord(record, "example.com.")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, input) {
+			t.Errorf("Round trip mismatch: expected %+v, got %+v", input, result)
+		}
+	}
+}
+
+func TestParseTLSA(t *testing.T) {
+	tests := []struct {
+		data         string
+		usage        uint8
+		selector     uint8
+		matchingType uint8
+		cert         string
+		ok           bool
+	}{
+		{"3 1 1 0C72AC70B745AC19998811B131D662C9AC69DBDBE7CB23E5B514B56664C5D3D6", 3, 1, 1, "0C72AC70B745AC19998811B131D662C9AC69DBDBE7CB23E5B514B56664C5D3D6", true},
+		{"2 0 2 abcdef01", 2, 0, 2, "abcdef01", true},
+		{"3  1  1  0C72AC70 B745AC19", 3, 1, 1, "0C72AC70B745AC19", true},
+		{"3 1 1 not-hex", 0, 0, 0, "", false},
+		{"3 1 1 ABC", 0, 0, 0, "", false},
+		{"256 1 1 ABCD", 0, 0, 0, "", false},
+		{"3 1 ABCD", 0, 0, 0, "", false},
+	}
+
+	for _, tt := range tests {
+		usage, selector, matchingType, cert, ok := parseTLSA(tt.data)
+		if ok != tt.ok || usage != tt.usage || selector != tt.selector || matchingType != tt.matchingType || cert != tt.cert {
+			t.Errorf("parseTLSA(%s) = (%d, %d, %d, %s, %v); expected (%d, %d, %d, %s, %v)",
+				tt.data, usage, selector, matchingType, cert, ok, tt.usage, tt.selector, tt.matchingType, tt.cert, tt.ok)
+		}
+	}
+}
+
+func TestTLSARoundTrip(t *testing.T) {
+	inputs := []godaddyRecord{
+		{Type: "TLSA", Name: "_25._tcp.mail", Data: "3 1 1 0C72AC70B745AC19998811B131D662C9AC69DBDBE7CB23E5B514B56664C5D3D6", TTL: 3600},
+		{Type: "TLSA", Name: "_443._tcp.www", Data: "2 0 1 e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", TTL: 3600},
+	}
+
+	for _, input := range inputs {
+		record := convertToLibdnsRecord(input)
+		rr, ok := record.(libdns.RR)
+		if !ok {
+			t.Fatalf("Expected libdns.RR, got %T", record)
+		}
+		if rr.Data != input.Data {
+			t.Errorf("Data mismatch: expected %s, got %s", input.Data, rr.Data)
+		}
+
+		result, err := convertFromLibdnsRecord(record, "example.com.")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, input) {
+			t.Errorf("Round trip mismatch: expected %+v, got %+v", input, result)
+		}
+	}
+
+	// Malformed data is returned unchanged when reading, but rejected when writing
+	malformed := godaddyRecord{Type: "TLSA", Name: "_25._tcp.mail", Data: "3 1 1 xyz", TTL: 3600}
+	if rr := convertToLibdnsRecord(malformed).RR(); rr.Data != malformed.Data {
+		t.Errorf("Data mismatch: expected %s, got %s", malformed.Data, rr.Data)
+	}
+	if _, err := convertFromLibdnsRecord(libdns.RR{Name: "_25._tcp.mail", Type: "TLSA", Data: "3 1 1 xyz"}, "example.com."); err == nil {
+		t.Errorf("Expected an error for malformed TLSA data")
+	}
+
+	// Hex digits are compared ignoring case
+	lower := libdns.RR{Name: "_25._tcp.mail", Type: "TLSA", Data: "3 1 1 0c72ac70"}
+	upper := libdns.RR{Name: "_25._tcp.mail", Type: "TLSA", Data: "3 1 1 0C72AC70"}
+	if RecordKey("example.com.", lower) != RecordKey("example.com.", upper) {
+		t.Errorf("Expected TLSA records differing only in hex case to have the same key")
+	}
+}
+
+func TestParseNAPTR(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected naptrData
+		ok       bool
+	}{
+		{
+			data:     `100 10 "u" "E2U+sip" "!^.*$!sip:info@example.com!" .`,
+			expected: naptrData{100, 10, "u", "E2U+sip", "!^.*$!sip:info@example.com!", "."},
+			ok:       true,
+		},
+		{
+			data:     `10 100 "S" "SIP+D2U" "" _sip._udp.example.com.`,
+			expected: naptrData{10, 100, "S", "SIP+D2U", "", "_sip._udp.example.com."},
+			ok:       true,
+		},
+		{
+			data:     `100 10 u E2U+sip !^.*$!sip:info@example.com! .`,
+			expected: naptrData{100, 10, "u", "E2U+sip", "!^.*$!sip:info@example.com!", "."},
+			ok:       true,
+		},
+		{
+			data:     `100 10 "u" "E2U+sip" "!^(.*) \"x\"$!\\1!" .`,
+			expected: naptrData{100, 10, "u", "E2U+sip", `!^(.*) "x"$!\1!`, "."},
+			ok:       true,
+		},
+		{data: `100 10 "u" "E2U+sip" "!^.*$!sip:info@example.com! .`},
+		{data: `100 10 "u" "E2U+sip" "!^.*$!sip:info@example.com!"`},
+		{data: `70000 10 "u" "E2U+sip" "" .`},
+	}
+
+	for _, tt := range tests {
+		naptr, ok := parseNAPTR(tt.data)
+		if ok != tt.ok || naptr != tt.expected {
+			t.Errorf("parseNAPTR(%s) = (%+v, %v); expected (%+v, %v)", tt.data, naptr, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestNAPTRRoundTrip(t *testing.T) {
+	inputs := []godaddyRecord{
+		{Type: "NAPTR", Name: "4.3.2.1", Data: `100 10 "u" "E2U+sip" "!^.*$!sip:info@example.com!" .`, TTL: 3600},
+		{Type: "NAPTR", Name: "@", Data: `10 100 "S" "SIP+D2U" "" _sip._udp.example.com.`, TTL: 3600},
+		{Type: "NAPTR", Name: "@", Data: `100 10 "u" "E2U+sip" "!^(.*) \"x\"$!\\1!" .`, TTL: 3600},
+	}
+
+	for _, input := range inputs {
+		record := convertToLibdnsRecord(input)
+		if rr := record.RR(); rr.Data != input.Data {
+			t.Errorf("Data mismatch: expected %s, got %s", input.Data, rr.Data)
+		}
+
+		result, err := convertFromLibdnsRecord(record, "example.com.")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, input) {
+			t.Errorf("Round trip mismatch: expected %+v, got %+v", input, result)
+		}
+	}
+
+	// Unquoted fields are normalized to the quoted form
+	record := libdns.RR{Name: "@", Type: "NAPTR", Data: `100 10 u E2U+sip !^.*$!sip:info@example.com! .`}
+	result, err := convertFromLibdnsRecord(record, "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := `100 10 "u" "E2U+sip" "!^.*$!sip:info@example.com!" .`; result.Data != expected {
+		t.Errorf("Data mismatch: expected %s, got %s", expected, result.Data)
+	}
+
+	if _, err := convertFromLibdnsRecord(libdns.RR{Name: "@", Type: "NAPTR", Data: `100 10 "u"`}, "example.com."); err == nil {
+		t.Errorf("Expected an error for malformed NAPTR data")
+	}
+}
+
+func TestParseDS(t *testing.T) {
+	sha256 := "E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D"
+
+	tests := []struct {
+		data     string
+		expected dsData
+		ok       bool
+	}{
+		{"2371 13 2 " + sha256, dsData{2371, 13, 2, sha256}, true},
+		{"2371 13 2 " + strings.ToLower(sha256), dsData{2371, 13, 2, strings.ToLower(sha256)}, true},
+		{"2371 13 2 " + sha256[:32] + " " + sha256[32:], dsData{2371, 13, 2, sha256}, true},
+		{"60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118", dsData{60485, 5, 1, "2BB183AF5F22588179A53B0A98631FAD1A292118"}, true},
+		{"2371 13 2 2BB183AF5F22588179A53B0A98631FAD1A292118", dsData{}, false},
+		{"2371 13 2 not-hex", dsData{}, false},
+		{"2371 256 2 " + sha256, dsData{}, false},
+		{"2371 13 0 " + sha256, dsData{}, false},
+		{"70000 13 2 " + sha256, dsData{}, false},
+		{"2371 13 2", dsData{}, false},
+	}
+
+	for _, tt := range tests {
+		ds, ok := parseDS(tt.data)
+		if ok != tt.ok || ds != tt.expected {
+			t.Errorf("parseDS(%s) = (%+v, %v); expected (%+v, %v)", tt.data, ds, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestDSRoundTrip(t *testing.T) {
+	inputs := []godaddyRecord{
+		{Type: "DS", Name: "child", Data: "2371 13 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D", TTL: 3600},
+		{Type: "DS", Name: "child", Data: "60485 5 1 2bb183af5f22588179a53b0a98631fad1a292118", TTL: 3600},
+	}
+
+	for _, input := range inputs {
+		record := convertToLibdnsRecord(input)
+		if rr := record.RR(); rr.Data != input.Data {
+			t.Errorf("Data mismatch: expected %s, got %s", input.Data, rr.Data)
+		}
+
+		result, err := convertFromLibdnsRecord(record, "example.com.")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, input) {
+			t.Errorf("Round trip mismatch: expected %+v, got %+v", input, result)
+		}
+	}
+
+	if _, err := convertFromLibdnsRecord(libdns.RR{Name: "child", Type: "DS", Data: "2371 13 2 ABCD"}, "example.com."); err == nil {
+		t.Errorf("Expected an error for a digest of the wrong length")
+	}
+}
+
+func TestParseSSHFP(t *testing.T) {
+	sha256 := "8F0C5D0A5D0B5B8C1E0E4A2D8E5C6A9B7F3A1C2D4E6F8091A2B3C4D5E6F70812"
+	sha1 := "DC3E1B0F2C6B4D5B9E7A8F0D1C2B3A4958677A6B"
+
+	tests := []struct {
+		data     string
+		expected sshfpData
+		ok       bool
+	}{
+		{"4 2 " + sha256, sshfpData{4, 2, sha256}, true},
+		{"1 1 " + strings.ToLower(sha1), sshfpData{1, 1, strings.ToLower(sha1)}, true},
+		{"4 2 " + sha256[:32] + " " + sha256[32:], sshfpData{4, 2, sha256}, true},
+		{"4 2 " + sha1, sshfpData{}, false},
+		{"4 3 " + sha256, sshfpData{}, false},
+		{"5 2 " + sha256, sshfpData{}, false},
+		{"0 2 " + sha256, sshfpData{}, false},
+		{"4 2 not-hex", sshfpData{}, false},
+		{"4 2", sshfpData{}, false},
+	}
+
+	for _, tt := range tests {
+		sshfp, ok := parseSSHFP(tt.data)
+		if ok != tt.ok || sshfp != tt.expected {
+			t.Errorf("parseSSHFP(%s) = (%+v, %v); expected (%+v, %v)", tt.data, sshfp, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestSSHFPRoundTrip(t *testing.T) {
+	inputs := []godaddyRecord{
+		{Type: "SSHFP", Name: "host", Data: "4 2 8F0C5D0A5D0B5B8C1E0E4A2D8E5C6A9B7F3A1C2D4E6F8091A2B3C4D5E6F70812", TTL: 3600},
+		{Type: "SSHFP", Name: "host", Data: "1 1 dc3e1b0f2c6b4d5b9e7a8f0d1c2b3a4958677a6b", TTL: 3600},
+	}
+
+	for _, input := range inputs {
+		record := convertToLibdnsRecord(input)
+		if rr := record.RR(); rr.Data != input.Data {
+			t.Errorf("Data mismatch: expected %s, got %s", input.Data, rr.Data)
+		}
+
+		result, err := convertFromLibdnsRecord(record, "example.com.")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, input) {
+			t.Errorf("Round trip mismatch: expected %+v, got %+v", input, result)
+		}
+	}
+
+	malformed := godaddyRecord{Type: "SSHFP", Name: "host", Data: "9 9 xyz", TTL: 3600}
+	if rr := convertToLibdnsRecord(malformed).RR(); rr.Data != malformed.Data {
+		t.Errorf("Data mismatch: expected %s, got %s", malformed.Data, rr.Data)
+	}
+	if _, err := convertFromLibdnsRecord(libdns.RR{Name: "host", Type: "SSHFP", Data: "9 9 xyz"}, "example.com."); err == nil {
+		t.Errorf("Expected an error for malformed SSHFP data")
+	}
+}
+
+func TestGetRecordsByTypeName(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		json.NewEncoder(w).Encode([]godaddyRecord{
+			{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 600},
+			{Type: "TXT", Name: "_acme-challenge", Data: "token-2", TTL: 600},
+		})
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+	records, err := provider.GetRecordsByTypeName(context.Background(), "example.com.", "TXT", "_acme-challenge.example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	for _, record := range records {
+		if _, ok := record.(libdns.TXT); !ok {
+			t.Errorf("Expected libdns.TXT, got %T", record)
+		}
+	}
+
+	if _, err := provider.GetRecordsByTypeName(context.Background(), "example.com.", "TXT", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"/v1/domains/example.com/records/TXT/_acme-challenge",
+		"/v1/domains/example.com/records/TXT",
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d: %v", len(expected), len(paths), paths)
+	}
+	for i, path := range expected {
+		if paths[i] != path {
+			t.Errorf("Request %d: expected path %s, got %s", i, path, paths[i])
+		}
+	}
+}
+
+// cancelingTransport serves every request with a canned response and cancels
+// the context once cancelAfter requests have been served, simulating a deadline
+// expiring between requests. Statuses optionally sets the status of each response.
+type cancelingTransport struct {
+	cancel      context.CancelFunc
+	cancelAfter int
+	statuses    []int
+	body        string
+	calls       int
+}
+
+func (ct *cancelingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := http.StatusOK
+	if ct.calls < len(ct.statuses) {
+		status = ct.statuses[ct.calls]
+	}
+	ct.calls++
+	if ct.calls >= ct.cancelAfter {
+		ct.cancel()
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(ct.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestExcludeSystemRecords(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "SOA", Name: "@", Data: "ns01.domaincontrol.com. dns.jomax.net. 2024010101 28800 7200 604800 600", TTL: 600},
+		{Type: "NS", Name: "@", Data: "ns01.domaincontrol.com", TTL: 3600},
+		{Type: "A", Name: "@", Data: "Parked", TTL: 600},
+		{Type: "CNAME", Name: "_domainconnect", Data: "_domainconnect.gd.domaincontrol.com", TTL: 3600},
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+		{Type: "NS", Name: "sub", Data: "ns1.other.com", TTL: 3600},
+		{Type: "CNAME", Name: "shop", Data: "shops.myshopify.com", TTL: 3600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		exclude  bool
+		expected []string
+	}{
+		{"Included by default", false, []string{"SOA/@", "NS/@", "A/@", "CNAME/_domainconnect", "A/www", "NS/sub", "CNAME/shop"}},
+		{"Excluded", true, []string{"A/www", "NS/sub", "CNAME/shop"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, ExcludeSystemRecords: tt.exclude}
+			records, err := provider.GetRecords(ctx, "example.com.")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var keys []string
+			for _, record := range records {
+				keys = append(keys, record.RR().Type+"/"+record.RR().Name)
+			}
+			if !slices.Equal(keys, tt.expected) {
+				t.Errorf("Records mismatch: expected %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+
+	// A reconciliation of the apex leaves GoDaddy's records alone
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, ExcludeSystemRecords: true}
+	_, _, remove, err := provider.ComputeDiff(ctx, "example.com.", []libdns.Record{
+		libdns.Address{Name: "@", IP: netip.MustParseAddr("192.0.2.2")},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(remove) != 0 {
+		t.Errorf("Expected the parked record not to be removed, got %v", remove)
+	}
+}
+
+func TestGetRecordsEmptyZone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		get  func() ([]libdns.Record, error)
+	}{
+		{"GetRecords", func() ([]libdns.Record, error) {
+			return provider.GetRecords(ctx, "example.com.")
+		}},
+		{"GetRecordsByTypeName", func() ([]libdns.Record, error) {
+			return provider.GetRecordsByTypeName(ctx, "example.com.", "TXT", "_acme-challenge")
+		}},
+		{"GetRecordsByName", func() ([]libdns.Record, error) {
+			return provider.GetRecordsByName(ctx, "example.com.", "www")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := tt.get()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if records == nil || len(records) != 0 {
+				t.Errorf("Expected an empty, non-nil slice, got %#v", records)
+			}
+		})
+	}
+
+	failing := Provider{APIToken: "test:secret", APIEndpoint: "http://127.0.0.1:0", MaxRetries: -1}
+	records, err := failing.GetRecords(ctx, "example.com.")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if records != nil {
+		t.Errorf("Expected nil records along with the error, got %#v", records)
+	}
+}
+
+func TestSortRecords(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "TXT", Name: "www", Data: "b", TTL: 600},
+		{Type: "A", Name: "www", Data: "192.0.2.2", TTL: 600},
+		{Type: "MX", Name: "@", Data: "10 mail.example.com", TTL: 3600},
+		{Type: "TXT", Name: "www", Data: "a", TTL: 600},
+		{Type: "A", Name: "api", Data: "192.0.2.3", TTL: 600},
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		sort     bool
+		expected []string
+	}{
+		{"Unsorted by default", false, []string{"TXT/www/b", "A/www/192.0.2.2", "MX/@/10 mail.example.com", "TXT/www/a", "A/api/192.0.2.3", "A/www/192.0.2.1"}},
+		{"Sorted", true, []string{"MX/@/10 mail.example.com", "A/api/192.0.2.3", "A/www/192.0.2.1", "A/www/192.0.2.2", "TXT/www/a", "TXT/www/b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, SortRecords: tt.sort}
+			records, err := provider.GetRecords(ctx, "example.com.")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var keys []string
+			for _, record := range records {
+				keys = append(keys, RecordKey("example.com.", record))
+			}
+			if !slices.Equal(keys, tt.expected) {
+				t.Errorf("Order mismatch: expected %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestAbsoluteNames(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "MX", Name: "@", Data: "10 mail.example.com", TTL: 3600},
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+		{Type: "SRV", Name: "@", Data: "sip.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5060, Service: "_sip", Protocol: "_tcp"},
+		{Type: "A", Name: "app.k8s", Data: "192.0.2.2", TTL: 600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		zone     string
+		absolute bool
+		expected []string
+	}{
+		{"Relative by default", "example.com.", false, []string{"@", "www", "_sip._tcp", "app.k8s"}},
+		{"Absolute", "example.com.", true, []string{"example.com.", "www.example.com.", "_sip._tcp.example.com.", "app.k8s.example.com."}},
+		{"Absolute in a delegated zone", "k8s.example.com.", true, []string{"app.k8s.example.com."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, AbsoluteNames: tt.absolute}
+			records, err := provider.GetRecords(ctx, tt.zone)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, record := range records {
+				names = append(names, record.RR().Name)
+			}
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("Names mismatch: expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+
+	// Records with absolute names can be passed back as they are
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, AbsoluteNames: true}
+	records, err := provider.GetRecordsByTypeName(ctx, "example.com.", "A", "www")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	deleted, err := provider.DeleteRecords(ctx, "example.com.", records)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deleted) != 1 || len(zone.records) != 3 {
+		t.Errorf("Expected www to be deleted, got %v deleted and %v remaining", deleted, zone.records)
+	}
+}
+
+func TestGetRecordsByName(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "TXT", Name: "_dmarc", Data: "v=DMARC1; p=none", TTL: 600},
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+		{Type: "CNAME", Name: "_dmarc.sub", Data: "dmarc.example.net", TTL: 600},
+		{Type: "MX", Name: "@", Data: "10 mail.example.com", TTL: 600},
+		{Type: "AAAA", Name: "www", Data: "2001:db8::1", TTL: 600},
+		{Type: "TXT", Name: "www", Data: "hello", TTL: 600},
+		{Type: "TXT", Name: "@", Data: "v=spf1 -all", TTL: 600},
+		{Type: "SRV", Name: "@", Data: "sip.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5060, Service: "_sip", Protocol: "_tcp"},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"_sip._tcp", []string{"SRV"}},
+		{"_sip._tcp.example.com.", []string{"SRV"}},
+		{"www", []string{"A", "AAAA", "TXT"}},
+		{"www.example.com.", []string{"A", "AAAA", "TXT"}},
+		{"_dmarc", []string{"TXT"}},
+		{"@", []string{"MX", "TXT"}},
+		{"", []string{"MX", "TXT"}},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := provider.GetRecordsByName(context.Background(), "example.com.", tt.name)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var types []string
+			for _, record := range records {
+				types = append(types, record.RR().Type)
+			}
+			if !slices.Equal(types, tt.expected) {
+				t.Errorf("Types mismatch: expected %v, got %v", tt.expected, types)
+			}
+		})
+	}
+}
+
+func TestContextCancellationStopsLoops(t *testing.T) {
+	t.Run("GetRecords", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		// Return a full page so that another page would be requested
+		transport := &cancelingTransport{cancel: cancel, body: `[{"type":"TXT","name":"a","data":"value","ttl":600}]`}
+		provider := Provider{APIToken: "test:secret", HTTPClient: &http.Client{Transport: transport}, PageSize: 1}
+
+		records, err := provider.GetRecords(ctx, "example.com.")
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if len(records) != 1 || transport.calls != 1 {
+			t.Errorf("Expected 1 record from 1 call, got %d records from %d calls", len(records), transport.calls)
+		}
+	})
+
+	t.Run("AppendRecords", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		// Reject the batch so that the records are appended one at a time
+		transport := &cancelingTransport{cancel: cancel, cancelAfter: 2, statuses: []int{http.StatusUnprocessableEntity}}
+		provider := Provider{APIToken: "test:secret", HTTPClient: &http.Client{Transport: transport}}
+
+		records, err := provider.AppendRecords(ctx, "example.com.", []libdns.Record{
+			libdns.TXT{Name: "a", Text: "one"},
+			libdns.TXT{Name: "b", Text: "two"},
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if len(records) != 1 || transport.calls != 2 {
+			t.Errorf("Expected 1 record from 2 calls, got %d records from %d calls", len(records), transport.calls)
+		}
+	})
+
+	t.Run("DeleteRecords", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		transport := &cancelingTransport{cancel: cancel, body: `[{"type":"TXT","name":"a","data":"value","ttl":600}]`}
+		provider := Provider{APIToken: "test:secret", HTTPClient: &http.Client{Transport: transport}}
+
+		records, err := provider.DeleteRecords(ctx, "example.com.", []libdns.Record{
+			libdns.TXT{Name: "a", Text: "value"},
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if len(records) != 0 || transport.calls != 1 {
+			t.Errorf("Expected no deletion after 1 call, got %d records from %d calls", len(records), transport.calls)
+		}
+	})
+}
+
+func TestAppendRecordsBatch(t *testing.T) {
+	var requests []string
+	var bodies [][]godaddyRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			// Records are read back after appending
+			w.Write([]byte("[]"))
+			return
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var body []godaddyRecord
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+
+		// Reject any request containing the invalid record
+		for _, gr := range body {
+			if gr.Data == "invalid" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				w.Write([]byte(`{"code":"INVALID_BODY","message":"Request body doesn't fulfill schema"}`))
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+	t.Run("Single PATCH for all records", func(t *testing.T) {
+		requests, bodies = nil, nil
+		records, err := provider.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+			libdns.TXT{Name: "a", Text: "one"},
+			libdns.TXT{Name: "b", Text: "two"},
+			libdns.TXT{Name: "c", Text: "three"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(records) != 3 {
+			t.Errorf("Expected 3 records, got %d", len(records))
+		}
+		if len(requests) != 1 || requests[0] != "PATCH /v1/domains/example.com/records" {
+			t.Fatalf("Expected a single PATCH request, got %v", requests)
+		}
+		if len(bodies[0]) != 3 {
+			t.Errorf("Expected 3 records in the request body, got %d", len(bodies[0]))
+		}
+	})
+
+	t.Run("Rejected batch falls back to single records", func(t *testing.T) {
+		requests, bodies = nil, nil
+		records, err := provider.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+			libdns.TXT{Name: "a", Text: "one"},
+			libdns.TXT{Name: "b", Text: "invalid"},
+			libdns.TXT{Name: "c", Text: "three"},
+		})
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		if !strings.Contains(err.Error(), "b.example.com") {
+			t.Errorf("Expected the error to name the rejected record, got %v", err)
+		}
+		if len(records) != 1 || records[0].RR().Name != "a" {
+			t.Errorf("Expected only record a to be appended, got %v", records)
+		}
+		if len(requests) != 3 {
+			t.Errorf("Expected 3 PATCH requests, got %v", requests)
+		}
+	})
+
+	t.Run("Fallback keeps values of a name together", func(t *testing.T) {
+		requests, bodies = nil, nil
+		records, err := provider.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+			libdns.TXT{Name: "a", Text: "one"},
+			libdns.TXT{Name: "b", Text: "invalid"},
+			libdns.TXT{Name: "a", Text: "two"},
+		})
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		if len(records) != 2 {
+			t.Errorf("Expected both values of a to be appended, got %v", records)
+		}
+		if len(requests) != 3 || len(bodies[1]) != 2 {
+			t.Errorf("Expected the batch and one PATCH per name, got %v with bodies %v", requests, bodies)
+		}
+	})
+}
+
+func TestAppendRecordsSameName(t *testing.T) {
+	zone := &fakeZone{}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+	_, err := provider.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+		libdns.TXT{Name: "_acme-challenge", Text: "token-2"},
+		libdns.TXT{Name: "_acme-challenge", Text: "token-3"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	records, err := provider.GetRecordsByTypeName(context.Background(), "example.com.", "TXT", "_acme-challenge")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var values []string
+	for _, record := range records {
+		values = append(values, record.RR().Data)
+	}
+	if expected := []string{"token-1", "token-2", "token-3"}; !slices.Equal(values, expected) {
+		t.Errorf("Values mismatch: expected %v, got %v", expected, values)
+	}
+}
+
+func TestConvertRecordMaxTTL(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    Provider
+		ttl         time.Duration
+		expectedTTL int
+		expectError bool
+	}{
+		{
+			name:        "TTL within default maximum",
+			provider:    Provider{},
+			ttl:         24 * time.Hour,
+			expectedTTL: 86400,
+		},
+		{
+			name:        "TTL above default maximum is rejected",
+			provider:    Provider{},
+			ttl:         8 * 24 * time.Hour,
+			expectError: true,
+		},
+		{
+			name:        "TTL above default maximum is clamped",
+			provider:    Provider{ClampMaxTTL: true},
+			ttl:         8 * 24 * time.Hour,
+			expectedTTL: 604800,
+		},
+		{
+			name:        "TTL above custom maximum is rejected",
+			provider:    Provider{MaxTTL: time.Hour},
+			ttl:         2 * time.Hour,
+			expectError: true,
+		},
+		{
+			name:        "TTL above custom maximum is clamped",
+			provider:    Provider{MaxTTL: time.Hour, ClampMaxTTL: true},
+			ttl:         2 * time.Hour,
+			expectedTTL: 3600,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := libdns.TXT{Name: "www", TTL: tt.ttl, Text: "hello"}
+			result, err := tt.provider.convertRecord(record, "example.com.")
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got TTL %d", result.TTL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.TTL != tt.expectedTTL {
+				t.Errorf("TTL mismatch: expected %d, got %d", tt.expectedTTL, result.TTL)
+			}
+		})
+	}
+}
+
+func TestConvertRecordMinTTL(t *testing.T) {
+	enforce, passThrough := true, false
+
+	tests := []struct {
+		name        string
+		provider    Provider
+		ttl         time.Duration
+		expectedTTL int
+	}{
+		{"Low TTL raised by default", Provider{}, 60 * time.Second, 600},
+		{"Low TTL raised when enforced", Provider{EnforceMinTTL: &enforce}, 60 * time.Second, 600},
+		{"Low TTL kept when not enforced", Provider{EnforceMinTTL: &passThrough}, 60 * time.Second, 60},
+		{"Missing TTL defaults to minimum when not enforced", Provider{EnforceMinTTL: &passThrough}, 0, 600},
+		{"High TTL unchanged when not enforced", Provider{EnforceMinTTL: &passThrough}, time.Hour, 3600},
+		{"Custom minimum raises TTL", Provider{MinTTL: time.Hour}, 20 * time.Minute, 3600},
+		{"Custom minimum keeps higher TTL", Provider{MinTTL: time.Hour}, 2 * time.Hour, 7200},
+		{"Lower custom minimum", Provider{MinTTL: 5 * time.Minute}, 60 * time.Second, 300},
+		{"Lower custom minimum keeps TTL above it", Provider{MinTTL: 5 * time.Minute}, 6 * time.Minute, 360},
+		{"Missing TTL defaults to custom minimum", Provider{MinTTL: time.Hour}, 0, 3600},
+		{"Custom minimum not enforced", Provider{MinTTL: time.Hour, EnforceMinTTL: &passThrough}, 20 * time.Minute, 1200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := libdns.TXT{Name: "www", TTL: tt.ttl, Text: "hello"}
+			result, err := tt.provider.convertRecord(record, "example.com.")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.TTL != tt.expectedTTL {
+				t.Errorf("TTL mismatch: expected %d, got %d", tt.expectedTTL, result.TTL)
+			}
+		})
+	}
+}
+
+func TestConvertRecordTypeTTLDefaults(t *testing.T) {
+	passThrough := false
+	defaults := map[string]time.Duration{"txt": 15 * time.Minute, "NS": 24 * time.Hour, "CNAME": time.Minute}
+
+	tests := []struct {
+		name        string
+		provider    Provider
+		record      libdns.Record
+		expectedTTL int
+	}{
+		{"Type default", Provider{TypeTTLDefaults: defaults, DefaultTTL: time.Hour}, libdns.TXT{Name: "_acme-challenge", Text: "token"}, 900},
+		{"Falls back to DefaultTTL", Provider{TypeTTLDefaults: defaults, DefaultTTL: time.Hour}, libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")}, 3600},
+		{"Falls back to MinTTL", Provider{TypeTTLDefaults: defaults}, libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")}, 600},
+		{"Explicit TTL wins", Provider{TypeTTLDefaults: defaults}, libdns.TXT{Name: "_acme-challenge", TTL: 2 * time.Hour, Text: "token"}, 7200},
+		{"Long type default", Provider{TypeTTLDefaults: defaults}, libdns.NS{Name: "sub", Target: "ns1.example.net."}, 86400},
+		{"Type default raised to MinTTL", Provider{TypeTTLDefaults: defaults}, libdns.CNAME{Name: "blog", Target: "www.example.com."}, 600},
+		{"Type default not enforced", Provider{TypeTTLDefaults: defaults, EnforceMinTTL: &passThrough}, libdns.CNAME{Name: "blog", Target: "www.example.com."}, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.provider.convertRecord(tt.record, "example.com.")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.TTL != tt.expectedTTL {
+				t.Errorf("TTL mismatch: expected %d, got %d", tt.expectedTTL, result.TTL)
+			}
+		})
+	}
+}
+
+func TestConvertToLibdnsRecordPreservesLowTTL(t *testing.T) {
+	// The minimum TTL only applies when writing; records read back from
+	// GoDaddy, e.g. ones imported with a lower TTL, keep their TTL as is.
+	tests := []godaddyRecord{
+		{Type: "A", Name: "www", Data: "192.168.1.1", TTL: 300},
+		{Type: "TXT", Name: "_acme-challenge", Data: "token", TTL: 300},
+		{Type: "MX", Name: "@", Data: "10 mail.example.com", TTL: 300},
+		{Type: "CNAME", Name: "blog", Data: "www.example.com", TTL: 60},
+	}
+
+	for _, input := range tests {
+		t.Run(input.Type, func(t *testing.T) {
+			record := convertToLibdnsRecord(input)
+			expected := time.Duration(input.TTL) * time.Second
+			if ttl := record.RR().TTL; ttl != expected {
+				t.Errorf("TTL mismatch: expected %s, got %s", expected, ttl)
+			}
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]godaddyRecord{{Type: "A", Name: "www", Data: "192.168.1.1", TTL: 300}})
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	records, err := provider.GetRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 1 || records[0].RR().TTL != 300*time.Second {
+		t.Errorf("Expected a single record with a 300s TTL, got %v", records)
+	}
+}
+
+func TestConvertRecordDefaultTTL(t *testing.T) {
+	passThrough := false
+
+	tests := []struct {
+		name        string
+		provider    Provider
+		ttl         time.Duration
+		expectedTTL int
+	}{
+		{"Zero TTL without DefaultTTL", Provider{}, 0, 600},
+		{"Zero TTL with DefaultTTL", Provider{DefaultTTL: time.Hour}, 0, 3600},
+		{"Explicit TTL ignores DefaultTTL", Provider{DefaultTTL: time.Hour}, 20 * time.Minute, 1200},
+		{"DefaultTTL below minimum is raised", Provider{DefaultTTL: 5 * time.Minute}, 0, 600},
+		{"DefaultTTL below minimum kept when not enforced", Provider{DefaultTTL: 5 * time.Minute, EnforceMinTTL: &passThrough}, 0, 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := libdns.TXT{Name: "www", TTL: tt.ttl, Text: "hello"}
+			result, err := tt.provider.convertRecord(record, "example.com.")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.TTL != tt.expectedTTL {
+				t.Errorf("TTL mismatch: expected %d, got %d", tt.expectedTTL, result.TTL)
+			}
+		})
+	}
+}
+
+func TestConvertFromLibdnsRecordAddressValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        libdns.Record
+		expectedType string
+		expectError  bool
+	}{
+		{
+			name:         "A record with IPv4",
+			input:        libdns.RR{Name: "www", Type: "A", Data: "192.168.1.1"},
+			expectedType: "A",
+		},
+		{
+			name:         "AAAA record with IPv6",
+			input:        libdns.RR{Name: "www", Type: "AAAA", Data: "2001:db8::1"},
+			expectedType: "AAAA",
+		},
+		{
+			name:        "AAAA record with IPv4",
+			input:       libdns.RR{Name: "www", Type: "AAAA", Data: "192.168.1.1"},
+			expectError: true,
+		},
+		{
+			name:        "A record with IPv6",
+			input:       libdns.RR{Name: "www", Type: "A", Data: "2001:db8::1"},
+			expectError: true,
+		},
+		{
+			name:        "A record with invalid IP",
+			input:       libdns.RR{Name: "www", Type: "A", Data: "not-an-ip"},
+			expectError: true,
+		},
+		{
+			name:        "Address without IP",
+			input:       libdns.Address{Name: "www"},
+			expectError: true,
+		},
+		{
+			name:         "Address with IPv6",
+			input:        libdns.Address{Name: "www", IP: netip.MustParseAddr("2001:db8::1")},
+			expectedType: "AAAA",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := convertFromLibdnsRecord(tt.input, "example.com.")
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Type != tt.expectedType {
+				t.Errorf("Type mismatch: expected %s, got %s", tt.expectedType, result.Type)
+			}
+		})
+	}
+}
+
+func TestReplaceAllRecords(t *testing.T) {
+	var method, path string
+	var body []godaddyRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			// The zone has no nameservers to keep
+			w.Write([]byte("[]"))
+			return
+		}
+		method, path = r.Method, r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	records, err := provider.ReplaceAllRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "@", TTL: time.Hour, IP: netip.MustParseAddr("192.168.1.1")},
+		libdns.TXT{Name: "_acme-challenge", TTL: time.Minute, Text: "token"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if method != http.MethodPut || path != "/v1/domains/example.com/records" {
+		t.Errorf("Unexpected request: %s %s", method, path)
+	}
+	if len(body) != 2 {
+		t.Fatalf("Expected 2 records in the request body, got %d", len(body))
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if ttl := records[1].RR().TTL; ttl != 600*time.Second {
+		t.Errorf("Expected the written TTL of 600s to be returned, got %v", ttl)
+	}
+}
+
+func TestReplaceAllRecordsApexNS(t *testing.T) {
+	tests := []struct {
+		name        string
+		records     []libdns.Record
+		expectedErr error
+	}{
+		{
+			name: "Records returned by GetRecords",
+			records: []libdns.Record{
+				libdns.NS{Name: "@", TTL: time.Hour, Target: "ns1.domaincontrol.com"},
+				libdns.NS{Name: "@", TTL: time.Hour, Target: "ns2.domaincontrol.com."},
+				libdns.Address{Name: "www", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.2")},
+			},
+		},
+		{
+			name: "Nameservers left out",
+			records: []libdns.Record{
+				libdns.Address{Name: "www", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.2")},
+			},
+		},
+		{
+			name: "Changed nameservers",
+			records: []libdns.Record{
+				libdns.NS{Name: "@", TTL: time.Hour, Target: "ns1.other.com"},
+				libdns.Address{Name: "www", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.2")},
+			},
+			expectedErr: ErrApexNS,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := &fakeZone{records: []godaddyRecord{
+				{Type: "NS", Name: "@", Data: "ns1.domaincontrol.com", TTL: 3600},
+				{Type: "NS", Name: "@", Data: "ns2.domaincontrol.com", TTL: 3600},
+				{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 3600},
+			}}
+			server := newFakeZoneServer(t, zone)
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+			_, err := provider.ReplaceAllRecords(context.Background(), "example.com.", tt.records)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				if zone.records[2].Data != "192.0.2.1" {
+					t.Errorf("Expected the zone to be unchanged, got %v", zone.records)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// The nameservers are kept either way
+			expected := []string{"NS/ns1.domaincontrol.com", "NS/ns2.domaincontrol.com", "A/192.0.2.2"}
+			var result []string
+			for _, gr := range zone.records {
+				result = append(result, gr.Type+"/"+gr.Data)
+			}
+			if !slices.Equal(result, expected) {
+				t.Errorf("Records mismatch: expected %v, got %v", expected, result)
+			}
+		})
+	}
+}
+
+// fakeZone is a minimal stateful stand-in for the GoDaddy records API of a
+// single domain: PATCH appends, PUT replaces and DELETE removes by type and name
+type fakeZone struct {
+	mu      sync.Mutex
+	records []godaddyRecord
+
+	// delay is added to every mutating request to simulate API latency
+	delay time.Duration
+}
+
+func newFakeZoneServer(t testing.TB, zone *fakeZone) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			time.Sleep(zone.delay)
+		}
+		zone.mu.Lock()
+		defer zone.mu.Unlock()
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/domains/example.com/records"), "/")
+		var recType, name string
+		if len(parts) > 1 {
+			recType = parts[1]
+		}
+		if len(parts) > 2 {
+			name = parts[2]
+		}
+		matches := func(gr godaddyRecord) bool {
+			return (recType == "" || gr.Type == recType) && (name == "" || gr.Name == name)
+		}
+
+		var body []godaddyRecord
+		if r.Body != nil {
+			json.NewDecoder(r.Body).Decode(&body)
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			result := []godaddyRecord{}
+			for _, gr := range zone.records {
+				if matches(gr) {
+					result = append(result, gr)
+				}
+			}
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			result = result[min(offset, len(result)):]
+			if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit < len(result) {
+				result = result[:limit]
+			}
+			// Responses include the read-only FQDN, which is never marshaled
+			served := make([]map[string]any, len(result))
+			for i, gr := range result {
+				data, _ := json.Marshal(gr)
+				json.Unmarshal(data, &served[i])
+				if gr.FQDN != "" {
+					served[i]["fqdn"] = gr.FQDN
+				}
+			}
+			json.NewEncoder(w).Encode(served)
+		case http.MethodPatch:
+			zone.records = append(zone.records, body...)
+		case http.MethodPut:
+			var kept []godaddyRecord
+			for _, gr := range zone.records {
+				if !matches(gr) {
+					kept = append(kept, gr)
+				}
+			}
+			for _, gr := range body {
+				if recType != "" {
+					gr.Type = recType
+				}
+				if name != "" {
+					gr.Name = name
+				}
+				kept = append(kept, gr)
+			}
+			zone.records = kept
+		case http.MethodDelete:
+			var kept []godaddyRecord
+			for _, gr := range zone.records {
+				if !matches(gr) {
+					kept = append(kept, gr)
+				}
+			}
+			zone.records = kept
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAppendRecordsKeepsExistingValues(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+	_, err := provider.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "token-2"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	records, err := provider.GetRecordsByTypeName(context.Background(), "example.com.", "TXT", "_acme-challenge")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected both TXT values to exist, got %v", records)
+	}
+	for i, text := range []string{"token-1", "token-2"} {
+		if data := records[i].RR().Data; data != text {
+			t.Errorf("Record %d: expected %s, got %s", i, text, data)
+		}
+	}
+}
+
+func TestConvertFromLibdnsRecordUnsupportedPTR(t *testing.T) {
+	_, err := convertFromLibdnsRecord(libdns.RR{Name: "1", Type: "PTR", Data: "host.example.com."}, "1.168.192.in-addr.arpa.")
+	if !errors.Is(err, ErrUnsupportedRecordType) {
+		t.Errorf("Expected ErrUnsupportedRecordType, got %v", err)
+	}
+}
+
+func TestConvertFromLibdnsRecordUnsupportedAlias(t *testing.T) {
+	for _, recType := range []string{"ALIAS", "ANAME", "alias"} {
+		_, err := convertFromLibdnsRecord(libdns.RR{Name: "@", Type: recType, Data: "target.example.net."}, "example.com.")
+		if !errors.Is(err, ErrUnsupportedRecordType) {
+			t.Errorf("%s: expected ErrUnsupportedRecordType, got %v", recType, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "A/AAAA") {
+			t.Errorf("%s: expected the error to suggest A/AAAA records, got %v", recType, err)
+		}
+	}
+}
+
+func TestConvertRecordCNAMEAtApex(t *testing.T) {
+	provider := Provider{}
+	for _, name := range []string{"@", "", "example.com.", "EXAMPLE.com"} {
+		_, err := provider.convertRecord(libdns.CNAME{Name: name, Target: "target.example.net."}, "example.com.")
+		if !errors.Is(err, ErrCNAMEAtApex) {
+			t.Errorf("%q: expected ErrCNAMEAtApex, got %v", name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "A/AAAA") {
+			t.Errorf("%q: expected the error to suggest A/AAAA records, got %v", name, err)
+		}
+	}
+
+	if _, err := provider.convertRecord(libdns.CNAME{Name: "www", Target: "target.example.net."}, "example.com."); err != nil {
+		t.Errorf("Unexpected error for a CNAME below the apex: %v", err)
+	}
+}
+
+func TestRecordExtraFields(t *testing.T) {
+	input := `{"type":"TXT","name":"www","data":"hello","ttl":600,"comment":"managed by hand","fqdn":"www.example.com"}`
+
+	var gr godaddyRecord
+	if err := json.Unmarshal([]byte(input), &gr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gr.Data != "hello" || gr.FQDN != "www.example.com" || string(gr.Extra["comment"]) != `"managed by hand"` || len(gr.Extra) != 1 {
+		t.Fatalf("Decoded record mismatch: got %+v", gr)
+	}
+
+	output, err := json.Marshal(gr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var expected, got map[string]any
+	json.Unmarshal([]byte(input), &expected)
+	json.Unmarshal(output, &got)
+	// The FQDN is read-only and not sent back
+	delete(expected, "fqdn")
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Round trip mismatch: expected %s, got %s", input, output)
+	}
+
+	// Records written back when deleting others of the same type and name keep
+	// their unknown fields
+	zone := &fakeZone{records: []godaddyRecord{gr, {Type: "TXT", Name: "www", Data: "bye", TTL: 600}}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	if _, err := provider.DeleteRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.TXT{Name: "www", Text: "bye"},
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(zone.records) != 1 || string(zone.records[0].Extra["comment"]) != `"managed by hand"` {
+		t.Errorf("Expected the kept record to keep its comment, got %+v", zone.records)
+	}
+}
+
+func TestWritesOmitFQDN(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(p *Provider) error
+	}{
+		{"UpdateTTL", func(p *Provider) error {
+			_, err := p.UpdateTTL(context.Background(), "example.com.", "TXT", "www", 2*time.Hour)
+			return err
+		}},
+		{"DeleteRecords", func(p *Provider) error {
+			_, err := p.DeleteRecords(context.Background(), "example.com.", []libdns.Record{libdns.TXT{Name: "www", Text: "bye"}})
+			return err
+		}},
+		{"SetRecords", func(p *Provider) error {
+			_, err := p.SetRecords(context.Background(), "example.com.", []libdns.Record{
+				libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"},
+			})
+			return err
+		}},
+		{"ReplaceAllRecords", func(p *Provider) error {
+			_, err := p.ReplaceAllRecords(context.Background(), "example.com.", []libdns.Record{libdns.TXT{Name: "www", Text: "hello"}})
+			return err
+		}},
+		{"Bulk delete", func(p *Provider) error {
+			p.BulkDeleteThreshold = 1
+			_, err := p.DeleteRecords(context.Background(), "example.com.", []libdns.Record{
+				libdns.TXT{Name: "www", Text: "bye"},
+				libdns.SRV{Service: "xmpp", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5222, Target: "xmpp.example.com"},
+			})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := &fakeZone{records: []godaddyRecord{
+				{Type: "NS", Name: "@", Data: "ns01.domaincontrol.com", TTL: 3600, FQDN: "example.com"},
+				{Type: "TXT", Name: "www", Data: "hello", TTL: 600, FQDN: "www.example.com"},
+				{Type: "TXT", Name: "www", Data: "bye", TTL: 600, FQDN: "www.example.com"},
+				{Type: "SRV", Name: "@", Data: "xmpp.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5222,
+					Service: "_xmpp", Protocol: "_tcp", FQDN: "_xmpp._tcp.example.com"},
+			}}
+			fake := newFakeZoneServer(t, zone)
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					data, _ := io.ReadAll(r.Body)
+					bodies = append(bodies, string(data))
+					r.Body = io.NopCloser(strings.NewReader(string(data)))
+				}
+				fake.Config.Handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			provider := &Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+			if err := tt.write(provider); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(bodies) == 0 {
+				t.Fatal("Expected a write request")
+			}
+			for _, body := range bodies {
+				if strings.Contains(body, "fqdn") {
+					t.Errorf("Expected no fqdn in the request body, got %s", body)
+				}
+			}
+		})
+	}
+}
+
+func TestFromRecordName(t *testing.T) {
+	tests := []struct {
+		name         string
+		zone         string
+		record       godaddyRecord
+		expectedName string
+		expectedOK   bool
+	}{
+		{"FQDN at the domain", "example.com.", godaddyRecord{Type: "A", Name: "www", FQDN: "www.example.com"}, "www", true},
+		{"FQDN of the apex", "example.com.", godaddyRecord{Type: "A", Name: "@", FQDN: "example.com."}, "@", true},
+		{"FQDN within a delegated zone", "k8s.example.com.", godaddyRecord{Type: "A", Name: "app.k8s", FQDN: "app.k8s.example.com"}, "app", true},
+		{"FQDN disambiguates the name", "k8s.example.com.", godaddyRecord{Type: "A", Name: "app", FQDN: "app.k8s.example.com"}, "app", true},
+		{"FQDN outside of a delegated zone", "k8s.example.com.", godaddyRecord{Type: "A", Name: "www", FQDN: "www.example.com"}, "", false},
+		{"FQDN ignoring case", "K8s.Example.com.", godaddyRecord{Type: "A", Name: "app.k8s", FQDN: "APP.k8s.example.COM"}, "APP", true},
+		{"FQDN of an SRV record", "example.com.", godaddyRecord{Type: "SRV", Name: "@", Service: "_sip", Protocol: "_tcp", FQDN: "_sip._tcp.example.com"}, "@", true},
+		{"Without FQDN", "k8s.example.com.", godaddyRecord{Type: "A", Name: "app.k8s"}, "app", true},
+		{"FQDN of another domain ignored", "k8s.example.com.", godaddyRecord{Type: "A", Name: "app.k8s", FQDN: "app.example.net"}, "app", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, ok := fromRecordName(tt.zone, tt.record)
+			if name != tt.expectedName || ok != tt.expectedOK {
+				t.Errorf("fromRecordName() = %q, %v; expected %q, %v", name, ok, tt.expectedName, tt.expectedOK)
+			}
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"type":"A","name":"app","data":"192.0.2.1","ttl":600,"fqdn":"app.k8s.example.com."},
+			{"type":"A","name":"www","data":"192.0.2.2","ttl":600,"fqdn":"www.example.com."}
+		]`))
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	records, err := provider.GetRecords(context.Background(), "k8s.example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 1 || records[0].RR().Name != "app" || records[0].RR().Data != "192.0.2.1" {
+		t.Errorf("Expected only the app record, got %v", records)
+	}
+}
+
+func TestRecordKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   libdns.Record
+		expected string
+	}{
+		{
+			name:     "Address",
+			record:   libdns.Address{Name: "www.example.com.", TTL: time.Hour, IP: netip.MustParseAddr("192.168.1.1")},
+			expected: "A/www/192.168.1.1",
+		},
+		{
+			name:     "TXT",
+			record:   libdns.TXT{Name: "_acme-challenge", Text: "token"},
+			expected: "TXT/_acme-challenge/token",
+		},
+		{
+			name:     "MX with trailing dot",
+			record:   libdns.MX{Name: "@", Preference: 10, Target: "mail.example.com."},
+			expected: "MX/@/10 mail.example.com",
+		},
+		{
+			name:     "CNAME with trailing dot",
+			record:   libdns.CNAME{Name: "blog", Target: "example.com."},
+			expected: "CNAME/blog/example.com",
+		},
+		{
+			name:     "Opaque RR",
+			record:   libdns.RR{Name: "mail", Type: "mx", Data: "10 mail.example.com."},
+			expected: "MX/mail/10 mail.example.com.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if key := RecordKey("example.com.", tt.record); key != tt.expected {
+				t.Errorf("RecordKey() = %s; expected %s", key, tt.expected)
+			}
+		})
+	}
+
+	// The TTL is not part of the key
+	a := libdns.TXT{Name: "www", TTL: time.Hour, Text: "hello"}
+	b := libdns.TXT{Name: "www.example.com.", TTL: time.Minute, Text: "hello"}
+	if RecordKey("example.com.", a) != RecordKey("example.com.", b) {
+		t.Errorf("Expected records differing only in TTL and name form to share a key")
+	}
+}
+
+func TestGroupRecordsSkipsDuplicates(t *testing.T) {
+	groups, err := (&Provider{}).groupRecords("example.com.", []libdns.Record{
+		libdns.TXT{Name: "www", Text: "hello"},
+		libdns.TXT{Name: "www.example.com.", Text: "hello"},
+		libdns.TXT{Name: "www", Text: "world"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(groups) != 1 || len(groups[0].Records) != 2 {
+		t.Fatalf("Expected 1 group with 2 records, got %+v", groups)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	var methods []string
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 600},
+	}}
+	fake := newFakeZoneServer(t, zone)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, DryRun: true}
+	ctx := context.Background()
+
+	appended, err := provider.AppendRecords(ctx, "example.com.", []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "token-2"},
+	})
+	if err != nil || len(appended) != 1 {
+		t.Fatalf("AppendRecords() = %v, %v; expected 1 record", appended, err)
+	}
+
+	set, err := provider.SetRecords(ctx, "example.com.", []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "token-3"},
+	})
+	if err != nil || len(set) != 1 {
+		t.Fatalf("SetRecords() = %v, %v; expected 1 record", set, err)
+	}
+
+	deleted, err := provider.DeleteRecords(ctx, "example.com.", []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+	})
+	if err != nil || len(deleted) != 1 {
+		t.Fatalf("DeleteRecords() = %v, %v; expected 1 record", deleted, err)
+	}
+
+	// Invalid records are still rejected
+	if _, err := provider.AppendRecords(ctx, "example.com.", []libdns.Record{
+		libdns.RR{Name: "www", Type: "AAAA", Data: "192.168.1.1"},
+	}); err == nil {
+		t.Errorf("Expected a validation error in dry run mode")
+	}
+
+	for _, method := range methods {
+		if method != http.MethodGet {
+			t.Errorf("Expected only GET requests in dry run mode, got %s", method)
+		}
+	}
+	if len(zone.records) != 1 || zone.records[0].Data != "token-1" {
+		t.Errorf("Expected the zone to be unchanged, got %+v", zone.records)
+	}
+}
+
+func TestAppendRecordsReturnsPersistedRecords(t *testing.T) {
+	tests := []struct {
+		name             string
+		records          []libdns.Record
+		expectedRequests []string
+	}{
+		{
+			name: "Single type and name",
+			records: []libdns.Record{
+				libdns.TXT{Name: "_acme-challenge.example.com.", TTL: 60 * time.Second, Text: "token"},
+			},
+			expectedRequests: []string{
+				"PATCH /v1/domains/example.com/records",
+				"GET /v1/domains/example.com/records/TXT/_acme-challenge",
+			},
+		},
+		{
+			name: "Several types and names",
+			records: []libdns.Record{
+				libdns.TXT{Name: "_acme-challenge.example.com.", TTL: 60 * time.Second, Text: "token"},
+				libdns.TXT{Name: "_acme-challenge.www", TTL: 60 * time.Second, Text: "token"},
+				libdns.Address{Name: "www", TTL: 60 * time.Second, IP: netip.MustParseAddr("192.0.2.1")},
+			},
+			expectedRequests: []string{
+				"PATCH /v1/domains/example.com/records",
+				"GET /v1/domains/example.com/records",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := &fakeZone{}
+			fake := newFakeZoneServer(t, zone)
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				fake.Config.Handler.ServeHTTP(w, r)
+				if r.Method == http.MethodPatch {
+					// Store a higher TTL than the 600s minimum sent, as if GoDaddy
+					// enforced its own, so that only a read back returns it
+					zone.mu.Lock()
+					for i := range zone.records {
+						zone.records[i].TTL = 1800
+					}
+					zone.mu.Unlock()
+				}
+			}))
+			defer server.Close()
+
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+			records, err := provider.AppendRecords(context.Background(), "example.com.", tt.records)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !slices.Equal(requests, tt.expectedRequests) {
+				t.Errorf("Requests mismatch: expected %v, got %v", tt.expectedRequests, requests)
+			}
+			if len(records) != len(tt.records) {
+				t.Fatalf("Expected %d records, got %d", len(tt.records), len(records))
+			}
+
+			for i, record := range records {
+				rr := record.RR()
+				if rr.TTL != 1800*time.Second {
+					t.Errorf("Expected the stored TTL of 1800s, got %v", rr.TTL)
+				}
+				if key := RecordKey("example.com.", tt.records[i]); RecordKey("example.com.", record) != key {
+					t.Errorf("Record mismatch: expected %s, got %+v", key, rr)
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentDeleteRecords(t *testing.T) {
+	zone := &fakeZone{}
+	var records []libdns.Record
+	for i := range 10 {
+		name := "host" + strconv.Itoa(i)
+		zone.records = append(zone.records, godaddyRecord{Type: "A", Name: name, Data: "192.0.2.1", TTL: 600})
+		records = append(records, libdns.Address{Name: name, IP: netip.MustParseAddr("192.0.2.1")})
+	}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, MaxConcurrency: 4}
+
+	deleted, err := provider.DeleteRecords(context.Background(), "example.com.", records)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deleted) != len(records) {
+		t.Fatalf("Expected %d deleted records, got %d", len(records), len(deleted))
+	}
+	for i, record := range deleted {
+		if name := record.RR().Name; name != records[i].RR().Name {
+			t.Errorf("Record %d name mismatch: expected %s, got %s", i, records[i].RR().Name, name)
+		}
+	}
+	if len(zone.records) != 0 {
+		t.Errorf("Expected an empty zone, got %v", zone.records)
+	}
+}
+
+func TestDeleteRecordsAcceptsOK(t *testing.T) {
+	// GoDaddy documents 204 for deletions, but some endpoints answer with 200
+	// and an empty body
+	var deletes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode([]godaddyRecord{{Type: "TXT", Name: "_acme-challenge", Data: "token", TTL: 600}})
+		case http.MethodDelete:
+			deletes++
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	deleted, err := provider.DeleteRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "token"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deleted) != 1 || deletes != 1 {
+		t.Errorf("Expected 1 deleted record with 1 request, got %v with %d requests", deleted, deletes)
+	}
+}
+
+func TestNSRecords(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "NS", Name: "@", Data: "ns01.domaincontrol.com", TTL: 3600},
+		{Type: "NS", Name: "@", Data: "ns02.domaincontrol.com", TTL: 3600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	// A delegation round-trips with its name and target
+	_, err := provider.SetRecords(ctx, "example.com.", []libdns.Record{
+		libdns.NS{Name: "sub.example.com.", TTL: time.Hour, Target: "ns1.other.com."},
+		libdns.NS{Name: "sub", TTL: time.Hour, Target: "ns2.other.com"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	records, err := provider.GetRecordsByTypeName(ctx, "example.com.", "NS", "sub")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []libdns.Record{
+		libdns.NS{Name: "sub", TTL: time.Hour, Target: "ns1.other.com"},
+		libdns.NS{Name: "sub", TTL: time.Hour, Target: "ns2.other.com"},
+	}
+	if !slices.Equal(records, expected) {
+		t.Errorf("Delegation mismatch: expected %v, got %v", expected, records)
+	}
+
+	// The apex NS records are read, but cannot be written or deleted
+	records, err = provider.GetRecordsByTypeName(ctx, "example.com.", "NS", "@")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("Expected the 2 apex NS records, got %v", records)
+	}
+	_, err = provider.AppendRecords(ctx, "example.com.", []libdns.Record{
+		libdns.NS{Name: "@", Target: "ns1.other.com."},
+	})
+	if !errors.Is(err, ErrApexNS) {
+		t.Errorf("Expected ErrApexNS, got %v", err)
+	}
+
+	deleted, err := provider.DeleteRecordsByType(ctx, "example.com.", "NS")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deleted) != 2 || len(zone.records) != 2 || zone.records[0].Name != "@" {
+		t.Errorf("Expected only the delegation to be deleted, deleted %v, kept %v", deleted, zone.records)
+	}
+
+	// The apex of a delegated zone is delegated from the registered domain
+	if _, err := provider.AppendRecords(ctx, "sub.example.com.", []libdns.Record{
+		libdns.NS{Name: "@", Target: "ns1.other.com."},
+	}); err != nil {
+		t.Errorf("Unexpected error writing the NS records of a delegated zone: %v", err)
+	}
+}
+
+func TestDeleteRecordsScopedListing(t *testing.T) {
+	tests := []struct {
+		name            string
+		records         []libdns.Record
+		expectedPath    string
+		expectedDeleted int
+	}{
+		{
+			"Single type and name",
+			[]libdns.Record{
+				libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+				libdns.TXT{Name: "_acme-challenge.example.com.", Text: "token-2"},
+			},
+			"/v1/domains/example.com/records/TXT/_acme-challenge",
+			2,
+		},
+		{
+			"Several names",
+			[]libdns.Record{
+				libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+				libdns.TXT{Name: "_acme-challenge.www", Text: "token-3"},
+			},
+			"/v1/domains/example.com/records",
+			2,
+		},
+		{
+			"Several types",
+			[]libdns.Record{
+				libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+				libdns.CNAME{Name: "_acme-challenge", Target: "acme.example.net."},
+			},
+			"/v1/domains/example.com/records",
+			1,
+		},
+		{
+			"Single SRV record",
+			[]libdns.Record{
+				libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"},
+			},
+			"/v1/domains/example.com/records/SRV/@",
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := &fakeZone{records: []godaddyRecord{
+				{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 600},
+				{Type: "TXT", Name: "_acme-challenge", Data: "token-2", TTL: 600},
+				{Type: "TXT", Name: "_acme-challenge.www", Data: "token-3", TTL: 600},
+				{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+				{Type: "SRV", Name: "@", Data: "sip.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5060, Service: "_sip", Protocol: "_tcp"},
+				{Type: "SRV", Name: "@", Data: "xmpp.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5222, Service: "_xmpp", Protocol: "_tcp"},
+			}}
+			fake := newFakeZoneServer(t, zone)
+			var listings []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					listings = append(listings, r.URL.Path)
+				}
+				fake.Config.Handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+			deleted, err := provider.DeleteRecords(context.Background(), "example.com.", tt.records)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(deleted) != tt.expectedDeleted {
+				t.Errorf("Expected %d deleted records, got %v", tt.expectedDeleted, deleted)
+			}
+			if len(listings) != 1 || listings[0] != tt.expectedPath {
+				t.Errorf("Expected a single listing of %s, got %v", tt.expectedPath, listings)
+			}
+		})
+	}
+}
+
+func TestDeleteRecordsBulk(t *testing.T) {
+	tests := []struct {
+		name            string
+		threshold       int
+		expectedMethods []string
+	}{
+		{"Disabled", 0, []string{"GET", "DELETE", "DELETE", "DELETE", "PUT"}},
+		{"Below threshold", 4, []string{"GET", "DELETE", "DELETE", "DELETE", "PUT"}},
+		{"Above threshold", 3, []string{"GET", "PUT"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := &fakeZone{records: []godaddyRecord{
+				{Type: "NS", Name: "@", Data: "ns01.domaincontrol.com", TTL: 3600},
+				{Type: "A", Name: "host0", Data: "192.0.2.1", TTL: 600},
+				{Type: "A", Name: "host1", Data: "192.0.2.1", TTL: 600},
+				{Type: "A", Name: "host2", Data: "192.0.2.1", TTL: 600},
+				{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+				{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 600},
+				{Type: "TXT", Name: "_acme-challenge", Data: "token-2", TTL: 600},
+				{Type: "MX", Name: "@", Data: "10 mail.example.com", TTL: 3600},
+			}}
+			fake := newFakeZoneServer(t, zone)
+			var methods []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				methods = append(methods, r.Method)
+				fake.Config.Handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, BulkDeleteThreshold: tt.threshold}
+			deleted, err := provider.DeleteRecords(context.Background(), "example.com.", []libdns.Record{
+				libdns.Address{Name: "host0", IP: netip.MustParseAddr("192.0.2.1")},
+				libdns.Address{Name: "host1", IP: netip.MustParseAddr("192.0.2.1")},
+				libdns.Address{Name: "host2", IP: netip.MustParseAddr("192.0.2.1")},
+				libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+				libdns.NS{Name: "@", Target: "ns01.domaincontrol.com."},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(deleted) != 4 {
+				t.Errorf("Expected 4 deleted records, got %v", deleted)
+			}
+			if !slices.Equal(methods, tt.expectedMethods) {
+				t.Errorf("Requests mismatch: expected %v, got %v", tt.expectedMethods, methods)
+			}
+
+			expected := []string{
+				"NS/@/ns01.domaincontrol.com",
+				"A/www/192.0.2.1",
+				"TXT/_acme-challenge/token-2",
+				"MX/@/10 mail.example.com",
+			}
+			var remaining []string
+			for _, gr := range zone.records {
+				remaining = append(remaining, RecordKey("example.com.", convertToLibdnsRecord(gr)))
+			}
+			slices.Sort(expected)
+			slices.Sort(remaining)
+			if !slices.Equal(remaining, expected) {
+				t.Errorf("Remaining records mismatch: expected %v, got %v", expected, remaining)
+			}
+		})
+	}
+}
+
+func TestDeleteRecordsBulkIgnoresCache(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "A", Name: "host0", Data: "192.0.2.1", TTL: 600},
+		{Type: "A", Name: "host1", Data: "192.0.2.1", TTL: 600},
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, BulkDeleteThreshold: 1, CacheTTL: time.Hour}
+	ctx := context.Background()
+
+	if _, err := provider.GetRecords(ctx, "example.com."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Created outside of the provider, so the cached records are stale
+	zone.records = append(zone.records, godaddyRecord{Type: "A", Name: "api", Data: "192.0.2.2", TTL: 600})
+
+	if _, err := provider.DeleteRecords(ctx, "example.com.", []libdns.Record{
+		libdns.Address{Name: "host0", IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "host1", IP: netip.MustParseAddr("192.0.2.1")},
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var remaining []string
+	for _, gr := range zone.records {
+		remaining = append(remaining, gr.Name)
+	}
+	if expected := []string{"www", "api"}; !slices.Equal(remaining, expected) {
+		t.Errorf("Remaining records mismatch: expected %v, got %v", expected, remaining)
+	}
+}
+
+func BenchmarkDeleteRecords(b *testing.B) {
+	benchmarks := []struct {
+		name                string
+		maxConcurrency      int
+		bulkDeleteThreshold int
+	}{
+		{"MaxConcurrency=1", 1, 0},
+		{"MaxConcurrency=8", 8, 0},
+		{"BulkDeleteThreshold=10", 1, 10},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			zone := &fakeZone{delay: time.Millisecond}
+			server := newFakeZoneServer(b, zone)
+			provider := Provider{
+				APIToken:            "test:secret",
+				APIEndpoint:         server.URL,
+				MaxConcurrency:      bm.maxConcurrency,
+				BulkDeleteThreshold: bm.bulkDeleteThreshold,
+			}
+
+			var records []libdns.Record
+			var stored []godaddyRecord
+			for i := range 50 {
+				name := "host" + strconv.Itoa(i)
+				stored = append(stored, godaddyRecord{Type: "A", Name: name, Data: "192.0.2.1", TTL: 600})
+				records = append(records, libdns.Address{Name: name, IP: netip.MustParseAddr("192.0.2.1")})
+			}
+
+			for b.Loop() {
+				zone.mu.Lock()
+				zone.records = append([]godaddyRecord(nil), stored...)
+				zone.mu.Unlock()
+
+				if _, err := provider.DeleteRecords(context.Background(), "example.com.", records); err != nil {
+					b.Fatalf("Unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestWildcardRecords(t *testing.T) {
+	zone := &fakeZone{}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	records := []libdns.Record{
+		libdns.Address{Name: "*.example.com.", IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.CNAME{Name: "*.sub", Target: "target.example.com."},
+		libdns.TXT{Name: "*", Text: "wildcard"},
+	}
+
+	if _, err := provider.AppendRecords(ctx, "example.com.", records); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedNames := []string{"*", "*.sub", "*"}
+	if len(zone.records) != len(expectedNames) {
+		t.Fatalf("Expected %d records, got %v", len(expectedNames), zone.records)
+	}
+	for i, name := range expectedNames {
+		if zone.records[i].Name != name {
+			t.Errorf("Record %d name mismatch: expected %s, got %s", i, name, zone.records[i].Name)
+		}
+	}
+
+	deleted, err := provider.DeleteRecords(ctx, "example.com.", records)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deleted) != len(records) {
+		t.Errorf("Expected %d deleted records, got %d", len(records), len(deleted))
+	}
+	if len(zone.records) != 0 {
+		t.Errorf("Expected an empty zone, got %v", zone.records)
+	}
+}
+
+func TestAppendRecordsIfAbsent(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 600},
+		{Type: "MX", Name: "@", Data: "10 mail.example.com", TTL: 3600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	records := []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+		libdns.TXT{Name: "_acme-challenge", Text: "token-2"},
+		libdns.TXT{Name: "_acme-challenge", Text: "token-2"},
+		libdns.MX{Name: "@", Preference: 10, Target: "mail.example.com."},
+	}
+
+	created, err := provider.AppendRecordsIfAbsent(ctx, "example.com.", records)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(created) != 1 || created[0].RR().Data != "token-2" {
+		t.Fatalf("Expected only token-2 to be created, got %v", created)
+	}
+	if len(zone.records) != 3 {
+		t.Errorf("Expected 3 records in the zone, got %v", zone.records)
+	}
+
+	// Re-running creates nothing
+	created, err = provider.AppendRecordsIfAbsent(ctx, "example.com.", records)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(created) != 0 {
+		t.Errorf("Expected no records to be created, got %v", created)
+	}
+	if len(zone.records) != 3 {
+		t.Errorf("Expected 3 records in the zone, got %v", zone.records)
+	}
+}
+
+func TestAppendRecordsWithStatus(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 600},
+		{Type: "MX", Name: "@", Data: "10 mail.example.com", TTL: 3600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	records := []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "token-1"},
+		libdns.TXT{Name: "_acme-challenge", Text: "token-2"},
+		libdns.TXT{Name: "_acme-challenge", Text: "token-2"},
+		libdns.MX{Name: "@", Preference: 10, Target: "mail.example.com."},
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+	}
+
+	statuses, err := provider.AppendRecordsWithStatus(ctx, "example.com.", records)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []bool{false, true, false, false, true}
+	if len(statuses) != len(expected) {
+		t.Fatalf("Status count mismatch: expected %d, got %d", len(expected), len(statuses))
+	}
+	for i, status := range statuses {
+		if status.Created != expected[i] {
+			t.Errorf("Created mismatch for record %d: expected %v, got %v", i, expected[i], status.Created)
+		}
+		if RecordKey("example.com.", status.Record) != RecordKey("example.com.", records[i]) {
+			t.Errorf("Record mismatch for record %d: expected %v, got %v", i, records[i], status.Record)
+		}
+	}
+	// Pre-existing records are returned as stored, with their TTL
+	if ttl := statuses[0].Record.RR().TTL; ttl != 10*time.Minute {
+		t.Errorf("TTL mismatch: expected %v, got %v", 10*time.Minute, ttl)
+	}
+	if len(zone.records) != 4 {
+		t.Errorf("Expected 4 records in the zone, got %v", zone.records)
+	}
+
+	// Re-running reports every record as already present
+	statuses, err = provider.AppendRecordsWithStatus(ctx, "example.com.", records)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, status := range statuses {
+		if status.Created {
+			t.Errorf("Expected record %d to already exist, got %v", i, status.Record)
+		}
+	}
+	if len(zone.records) != 4 {
+		t.Errorf("Expected 4 records in the zone, got %v", zone.records)
+	}
+}
+
+func TestTXTRoundTrip(t *testing.T) {
+	values := []string{
+		// DKIM with a 2048-bit RSA public key
+		"v=DKIM1; k=rsa; p=MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAitWewBHl+J5rLv/X2qOIk/r8acPZHdC8q8dRgSXeZzZE1HO7tW8/ka+aPwtngOh4kDkBBD00qV1LP0eFkbV3do/T20iM2jrr0981IFSOukKqej/8M9Myd9cL/eckkmuP43qPTTRoPymnclodQ+IabJCKGhEBUAGTJo38vG2O9ILVP8B1ZmLMv39p7SbUU/4LDEPTbnfi43vl0Wm59NYpzm8+lXxqB84tXsJtsGQDXaoJL5KHzrAP7/IADQdx/Fr7qHhAqBzi/7bwMUL6jdLap4H6A76vPn+zmxyp0IKbpS60ju/Z7YM7XU4pAeEizNQsAcF9GMqlXZPCe8YVjXlj/wIDAQAB",
+		"v=spf1 include:_spf.google.com include:mailgun.org include:sendgrid.net ip4:192.0.2.0/24 ~all",
+		"v=DMARC1; p=quarantine; rua=mailto:dmarc@example.com; ruf=mailto:forensic@example.com; pct=100",
+		`say "hello" \ & <goodbye>`,
+	}
+
+	for _, value := range values {
+		input := godaddyRecord{Type: "TXT", Name: "selector._domainkey", Data: value, TTL: 3600}
+
+		record := convertToLibdnsRecord(input)
+		txt, ok := record.(libdns.TXT)
+		if !ok {
+			t.Fatalf("Expected libdns.TXT, got %T", record)
+		}
+		if txt.Text != value {
+			t.Errorf("Text mismatch: expected %s, got %s", value, txt.Text)
+		}
+
+		result, err := convertFromLibdnsRecord(record, "example.com.")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, input) {
+			t.Errorf("Round trip mismatch: expected %+v, got %+v", input, result)
+		}
+	}
+
+	// Also round-trip the values through the JSON API
+	zone := &fakeZone{}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+	var records []libdns.Record
+	for _, value := range values {
+		records = append(records, libdns.TXT{Name: "txt", Text: value})
+	}
+	if _, err := provider.AppendRecords(context.Background(), "example.com.", records); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fetched, err := provider.GetRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(fetched) != len(values) {
+		t.Fatalf("Expected %d records, got %d", len(values), len(fetched))
+	}
+	for i, value := range values {
+		if text := fetched[i].(libdns.TXT).Text; text != value {
+			t.Errorf("Record %d text mismatch: expected %s, got %s", i, value, text)
+		}
+	}
+}
+
+func TestAppendRecordsReturnsPartialResults(t *testing.T) {
+	zone := &fakeZone{}
+	fakeServer := newFakeZoneServer(t, zone)
+
+	// Reject the batch and every record from the fifth one on
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			body, _ := io.ReadAll(r.Body)
+			var grs []godaddyRecord
+			json.Unmarshal(body, &grs)
+			for _, gr := range grs {
+				if n, _ := strconv.Atoi(strings.TrimPrefix(gr.Name, "host")); n >= 4 {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					w.Write([]byte(`{"code":"INVALID_BODY","message":"Request body doesn't fulfill schema"}`))
+					return
+				}
+			}
+			r.Body = io.NopCloser(strings.NewReader(string(body)))
+		}
+		fakeServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var records []libdns.Record
+	for i := range 10 {
+		records = append(records, libdns.TXT{Name: "host" + strconv.Itoa(i), Text: "value"})
+	}
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	appended, err := provider.AppendRecords(context.Background(), "example.com.", records)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if !strings.Contains(err.Error(), "host4.example.com") {
+		t.Errorf("Expected the error to name the fifth record, got %v", err)
+	}
+	if len(appended) != 4 {
+		t.Fatalf("Expected the first 4 records to be returned, got %v", appended)
+	}
+	for i, record := range appended {
+		if name := record.RR().Name; name != "host"+strconv.Itoa(i) {
+			t.Errorf("Record %d name mismatch: expected host%d, got %s", i, i, name)
+		}
+	}
+	if len(zone.records) != len(appended) {
+		t.Errorf("Expected the zone to hold the %d returned records, got %v", len(appended), zone.records)
+	}
+}
+
+func TestSetRecordsRemovesStaleRecords(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+		{Type: "A", Name: "www", Data: "192.0.2.2", TTL: 600},
+		{Type: "AAAA", Name: "www", Data: "2001:db8::1", TTL: 600},
+		{Type: "A", Name: "api", Data: "192.0.2.3", TTL: 600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+	_, err := provider.SetRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The stale A record of www is removed; other types and names are untouched
+	expected := map[string]bool{
+		"A/www/192.0.2.1":      true,
+		"AAAA/www/2001:db8::1": true,
+		"A/api/192.0.2.3":      true,
+	}
+	if len(zone.records) != len(expected) {
+		t.Fatalf("Expected %d records, got %v", len(expected), zone.records)
+	}
+	for _, gr := range zone.records {
+		if key := gr.Type + "/" + gr.Name + "/" + gr.Data; !expected[key] {
+			t.Errorf("Unexpected record left in the zone: %s", key)
+		}
+	}
+}
+
+func TestSetRecordsSRVServices(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "SRV", Name: "@", Data: "xmpp.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5222, Service: "_xmpp", Protocol: "_tcp"},
+		{Type: "SRV", Name: "@", Data: "old.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5060, Service: "_sip", Protocol: "_tcp"},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+	sip := libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"}
+	if _, err := provider.SetRecords(context.Background(), "example.com.", []libdns.Record{sip}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The _sip._tcp record is replaced while the _xmpp._tcp record is kept
+	expected := map[string]bool{
+		"_xmpp/xmpp.example.com": true,
+		"_sip/sip.example.com":   true,
+	}
+	if len(zone.records) != len(expected) {
+		t.Fatalf("Expected %d records, got %v", len(expected), zone.records)
+	}
+	for _, gr := range zone.records {
+		if key := gr.Service + "/" + gr.Data; !expected[key] {
+			t.Errorf("Unexpected record left in the zone: %s", key)
+		}
+	}
+
+	// Setting the same record again is recognized as unchanged
+	var writes int
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes++
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer counting.Close()
+	provider.APIEndpoint = counting.URL
+	if _, err := provider.SetRecords(context.Background(), "example.com.", []libdns.Record{sip}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if writes != 0 {
+		t.Errorf("Expected no writes for an unchanged SRV record, got %d", writes)
+	}
+}
+
+func TestSetRecordsSkipsUnchangedGroups(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+		{Type: "A", Name: "www", Data: "192.0.2.2", TTL: 600},
+		{Type: "A", Name: "api", Data: "192.0.2.3", TTL: 600},
+		{Type: "TXT", Name: "@", Data: "v=spf1 -all", TTL: 3600},
+	}}
+	fake := newFakeZoneServer(t, zone)
+	var puts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			puts = append(puts, r.URL.Path)
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, MaxConcurrency: 4}
+	set, err := provider.SetRecords(context.Background(), "example.com.", []libdns.Record{
+		// Unchanged, in a different order
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.2"), TTL: 600 * time.Second},
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+		// Different data
+		libdns.Address{Name: "api", IP: netip.MustParseAddr("192.0.2.4")},
+		// Different TTL
+		libdns.TXT{Name: "@", Text: "v=spf1 -all", TTL: time.Hour * 2},
+		// New group
+		libdns.Address{Name: "new", IP: netip.MustParseAddr("192.0.2.5"), TTL: 60 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPuts := map[string]bool{
+		"/v1/domains/example.com/records/A/api": true,
+		"/v1/domains/example.com/records/TXT/@": true,
+		"/v1/domains/example.com/records/A/new": true,
+	}
+	if len(puts) != len(expectedPuts) {
+		t.Errorf("Expected %d PUT requests, got %v", len(expectedPuts), puts)
+	}
+	for _, path := range puts {
+		if !expectedPuts[path] {
+			t.Errorf("Unexpected PUT request: %s", path)
+		}
+	}
+
+	// The returned records are the ones stored, with the minimum TTL applied
+	expected := []string{
+		"A/www/192.0.2.2/10m0s",
+		"A/www/192.0.2.1/10m0s",
+		"A/api/192.0.2.4/10m0s",
+		"TXT/@/v=spf1 -all/2h0m0s",
+		"A/new/192.0.2.5/10m0s",
+	}
+	if len(set) != len(expected) {
+		t.Fatalf("Expected %d records, got %v", len(expected), set)
+	}
+	for i, record := range set {
+		rr := record.RR()
+		if got := rr.Type + "/" + rr.Name + "/" + rr.Data + "/" + rr.TTL.String(); got != expected[i] {
+			t.Errorf("Record %d mismatch: expected %s, got %s", i, expected[i], got)
+		}
+	}
+}
+
+func TestSetRecordsIgnoresCache(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, CacheTTL: time.Hour}
+	ctx := context.Background()
+
+	if _, err := provider.GetRecords(ctx, "example.com."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Changed outside of the provider, so the cached records are stale
+	zone.records[0].Data = "192.0.2.9"
+
+	if _, err := provider.SetRecords(ctx, "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(zone.records) != 1 || zone.records[0].Data != "192.0.2.1" {
+		t.Errorf("Expected the record to be written, got %v", zone.records)
+	}
+}
+
+func BenchmarkSetRecords(b *testing.B) {
+	for _, concurrency := range []int{1, 8} {
+		b.Run("MaxConcurrency="+strconv.Itoa(concurrency), func(b *testing.B) {
+			zone := &fakeZone{delay: time.Millisecond}
+			server := newFakeZoneServer(b, zone)
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, MaxConcurrency: concurrency}
+
+			// A 500-record zone, of which every tenth name gets a new address
+			var records []libdns.Record
+			var stored []godaddyRecord
+			for i := range 500 {
+				name := "host" + strconv.Itoa(i)
+				stored = append(stored, godaddyRecord{Type: "A", Name: name, Data: "192.0.2.1", TTL: 600})
+				ip := netip.MustParseAddr("192.0.2.1")
+				if i%10 == 0 {
+					ip = netip.MustParseAddr("192.0.2.2")
+				}
+				records = append(records, libdns.Address{Name: name, IP: ip})
+			}
+
+			for b.Loop() {
+				zone.mu.Lock()
+				zone.records = append([]godaddyRecord(nil), stored...)
+				zone.mu.Unlock()
+
+				if _, err := provider.SetRecords(context.Background(), "example.com.", records); err != nil {
+					b.Fatalf("Unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSetMXRecords(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "MX", Name: "@", Data: "10 old.example.com", TTL: 3600},
+		{Type: "TXT", Name: "@", Data: "v=spf1 -all", TTL: 3600},
+	}}
+	fake := newFakeZoneServer(t, zone)
+	var body []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			data, _ := io.ReadAll(r.Body)
+			body = nil
+			json.Unmarshal(data, &body)
+			r.Body = io.NopCloser(strings.NewReader(string(data)))
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	var requests []string
+	provider := Provider{
+		APIToken:    "test:secret",
+		APIEndpoint: server.URL,
+		OnRequest:   func(r *http.Request) { requests = append(requests, r.Method+" "+r.URL.Path) },
+	}
+	ctx := context.Background()
+
+	records, err := provider.SetMXRecords(ctx, "example.com.", "example.com.", []libdns.MX{
+		{Preference: 10, Target: "mx1.example.com.", TTL: time.Hour},
+		{Preference: 20, Target: "mx2.example.com.", TTL: time.Hour},
+		{Preference: 30, Target: "backup.example.net", TTL: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("Expected 3 records, got %v", records)
+	}
+	if expected := []string{"PUT /v1/domains/example.com/records/MX/@"}; !slices.Equal(requests, expected) {
+		t.Errorf("Requests mismatch: expected %v, got %v", expected, requests)
+	}
+
+	// The preferences are sent in the priority field, with the targets alone
+	// in the data
+	expectedBody := []map[string]any{
+		{"type": "MX", "name": "@", "data": "mx1.example.com.", "priority": 10.0, "ttl": 3600.0},
+		{"type": "MX", "name": "@", "data": "mx2.example.com.", "priority": 20.0, "ttl": 3600.0},
+		{"type": "MX", "name": "@", "data": "backup.example.net", "priority": 30.0, "ttl": 3600.0},
+	}
+	if !reflect.DeepEqual(body, expectedBody) {
+		t.Errorf("Body mismatch: expected %v, got %v", expectedBody, body)
+	}
+
+	stored, err := provider.GetRecordsByTypeName(ctx, "example.com.", "MX", "@")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var preferences []uint16
+	for _, record := range stored {
+		preferences = append(preferences, record.(libdns.MX).Preference)
+	}
+	if expected := []uint16{10, 20, 30}; !slices.Equal(preferences, expected) {
+		t.Errorf("Preferences mismatch: expected %v, got %v", expected, preferences)
+	}
+	if len(zone.records) != 4 {
+		t.Errorf("Expected the TXT record to be kept, got %v", zone.records)
+	}
+
+	invalid := []struct {
+		name    string
+		records []libdns.MX
+	}{
+		{"Empty target", []libdns.MX{{Preference: 10}}},
+		{"Invalid target", []libdns.MX{{Preference: 10, Target: "mail..example.com"}}},
+		{"Duplicate target", []libdns.MX{{Preference: 10, Target: "mx1.example.com"}, {Preference: 20, Target: "MX1.example.com."}}},
+		{"Null MX with preference", []libdns.MX{{Preference: 10, Target: "."}}},
+		{"Null MX with other records", []libdns.MX{{Preference: 0, Target: "."}, {Preference: 10, Target: "mx1.example.com"}}},
+	}
+	for _, tt := range invalid {
+		if _, err := provider.SetMXRecords(ctx, "example.com.", "@", tt.records); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	if _, err := provider.SetMXRecords(ctx, "example.com.", "@", []libdns.MX{{Preference: 0, Target: "."}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedBody = []map[string]any{
+		{"type": "MX", "name": "@", "data": ".", "priority": 0.0, "ttl": 600.0},
+	}
+	if !reflect.DeepEqual(body, expectedBody) {
+		t.Errorf("Body mismatch: expected %v, got %v", expectedBody, body)
+	}
+
+	if _, err := provider.SetMXRecords(ctx, "example.com.", "@", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(zone.records) != 1 {
+		t.Errorf("Expected the MX records to be deleted, got %v", zone.records)
+	}
+}
+
+func TestRecordLimits(t *testing.T) {
+	txt := func(name, text string) libdns.Record {
+		return libdns.TXT{Name: name, Text: text}
+	}
+
+	tests := []struct {
+		name        string
+		limits      map[string]int
+		write       func(p *Provider) ([]libdns.Record, error)
+		expectedErr string
+	}{
+		{
+			name:   "Append within the limit",
+			limits: map[string]int{"txt": 3},
+			write: func(p *Provider) ([]libdns.Record, error) {
+				return p.AppendRecords(context.Background(), "example.com.", []libdns.Record{txt("_acme-challenge", "token-3")})
+			},
+		},
+		{
+			name:   "Append exceeding the limit",
+			limits: map[string]int{"txt": 3},
+			write: func(p *Provider) ([]libdns.Record, error) {
+				return p.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+					txt("_acme-challenge", "token-3"),
+					txt("www", "other"),
+				})
+			},
+			expectedErr: "TXT",
+		},
+		{
+			name:   "Set replacing records",
+			limits: map[string]int{"TXT": 3},
+			write: func(p *Provider) ([]libdns.Record, error) {
+				return p.SetRecords(context.Background(), "example.com.", []libdns.Record{
+					txt("_acme-challenge", "token-3"),
+					txt("_acme-challenge", "token-4"),
+					txt("_acme-challenge", "token-5"),
+				})
+			},
+		},
+		{
+			name:   "Set exceeding the limit",
+			limits: map[string]int{"TXT": 3},
+			write: func(p *Provider) ([]libdns.Record, error) {
+				return p.SetRecords(context.Background(), "example.com.", []libdns.Record{
+					txt("www", "token-3"),
+					txt("www", "token-4"),
+				})
+			},
+			expectedErr: "TXT",
+		},
+		{
+			name:   "Replace exceeding the limit",
+			limits: map[string]int{"TXT": 1},
+			write: func(p *Provider) ([]libdns.Record, error) {
+				return p.ReplaceAllRecords(context.Background(), "example.com.", []libdns.Record{
+					txt("www", "token-3"),
+					txt("www", "token-4"),
+				})
+			},
+			expectedErr: "TXT",
+		},
+		{
+			name:   "Set SRV keeping the other services",
+			limits: map[string]int{"SRV": 2},
+			write: func(p *Provider) ([]libdns.Record, error) {
+				return p.SetRecords(context.Background(), "example.com.", []libdns.Record{
+					libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"},
+				})
+			},
+			expectedErr: "SRV",
+		},
+		{
+			name:   "Other types unaffected by a type above its limit",
+			limits: map[string]int{"TXT": 1, "A": 1},
+			write: func(p *Provider) ([]libdns.Record, error) {
+				return p.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+					libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := &fakeZone{records: []godaddyRecord{
+				{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 600},
+				{Type: "TXT", Name: "_acme-challenge", Data: "token-2", TTL: 600},
+				{Type: "SRV", Name: "@", Data: "xmpp.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5222, Service: "_xmpp", Protocol: "_tcp"},
+				{Type: "SRV", Name: "@", Data: "imap.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 143, Service: "_imap", Protocol: "_tcp"},
+			}}
+			fake := newFakeZoneServer(t, zone)
+			modifying := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					modifying++
+				}
+				fake.Config.Handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, RecordLimits: tt.limits}
+			_, err := tt.write(&provider)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if modifying == 0 {
+					t.Error("Expected the records to be written")
+				}
+				return
+			}
+			if !errors.Is(err, ErrRecordLimit) {
+				t.Fatalf("Expected ErrRecordLimit, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.expectedErr+" records") {
+				t.Errorf("Expected the error to name the type, got %v", err)
+			}
+			if modifying != 0 {
+				t.Errorf("Expected no modifying request, got %d", modifying)
+			}
+		})
+	}
+}
+
+func TestUpdateTTL(t *testing.T) {
+	tests := []struct {
+		name             string
+		recType          string
+		recName          string
+		ttl              time.Duration
+		expectedTTL      int
+		expectedRequests []string
+		expectedErr      error
+	}{
+		{
+			name:        "Changes TTL",
+			recType:     "txt",
+			recName:     "_acme-challenge.example.com.",
+			ttl:         time.Hour,
+			expectedTTL: 3600,
+			expectedRequests: []string{
+				"GET /v1/domains/example.com/records/TXT/_acme-challenge",
+				"PUT /v1/domains/example.com/records/TXT/_acme-challenge",
+			},
+		},
+		{
+			name:        "Zero selects the default",
+			recType:     "TXT",
+			recName:     "_acme-challenge",
+			ttl:         0,
+			expectedTTL: 600,
+			expectedRequests: []string{
+				"GET /v1/domains/example.com/records/TXT/_acme-challenge",
+				"PUT /v1/domains/example.com/records/TXT/_acme-challenge",
+			},
+		},
+		{
+			name:        "Unchanged TTL",
+			recType:     "TXT",
+			recName:     "_acme-challenge",
+			ttl:         30 * time.Minute,
+			expectedTTL: 1800,
+			expectedRequests: []string{
+				"GET /v1/domains/example.com/records/TXT/_acme-challenge",
+			},
+		},
+		{
+			name:    "Missing records",
+			recType: "TXT",
+			recName: "missing",
+			ttl:     time.Hour,
+			expectedRequests: []string{
+				"GET /v1/domains/example.com/records/TXT/missing",
+			},
+			expectedErr: ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := &fakeZone{records: []godaddyRecord{
+				{Type: "TXT", Name: "_acme-challenge", Data: "token-1", TTL: 1800},
+				{Type: "TXT", Name: "_acme-challenge", Data: "token-2", TTL: 1800},
+				{Type: "TXT", Name: "www", Data: "other", TTL: 1800},
+			}}
+			fake := newFakeZoneServer(t, zone)
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				fake.Config.Handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+			updated, err := provider.UpdateTTL(context.Background(), "example.com.", tt.recType, tt.recName, tt.ttl)
+			if !slices.Equal(requests, tt.expectedRequests) {
+				t.Errorf("Requests mismatch: expected %v, got %v", tt.expectedRequests, requests)
+			}
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(updated) != 2 {
+				t.Fatalf("Expected 2 updated records, got %v", updated)
+			}
+
+			expected := map[string]int{"_acme-challenge/token-1": tt.expectedTTL, "_acme-challenge/token-2": tt.expectedTTL, "www/other": 1800}
+			if len(zone.records) != len(expected) {
+				t.Fatalf("Expected %d records, got %v", len(expected), zone.records)
+			}
+			for _, gr := range zone.records {
+				ttl, ok := expected[gr.Name+"/"+gr.Data]
+				if !ok {
+					t.Errorf("Unexpected record %v", gr)
+				} else if gr.TTL != ttl {
+					t.Errorf("TTL mismatch for %s/%s: expected %d, got %d", gr.Name, gr.Data, ttl, gr.TTL)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateTTLSRV(t *testing.T) {
+	tests := []struct {
+		name        string
+		recName     string
+		expectedTTL map[string]int
+		expectedErr error
+	}{
+		{
+			name:        "One service",
+			recName:     "_sip._tcp",
+			expectedTTL: map[string]int{"_sip": 3600, "_xmpp": 1800},
+		},
+		{
+			name:        "Fully qualified",
+			recName:     "_sip._tcp.example.com.",
+			expectedTTL: map[string]int{"_sip": 3600, "_xmpp": 1800},
+		},
+		{
+			name:        "Every service at the name",
+			recName:     "@",
+			expectedTTL: map[string]int{"_sip": 3600, "_xmpp": 3600},
+		},
+		{
+			name:        "Missing service",
+			recName:     "_imap._tcp",
+			expectedErr: ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := &fakeZone{records: []godaddyRecord{
+				{Type: "SRV", Name: "@", Data: "sip.example.com", TTL: 1800, Priority: 10, Weight: 5, Port: 5060, Service: "_sip", Protocol: "_tcp"},
+				{Type: "SRV", Name: "@", Data: "xmpp.example.com", TTL: 1800, Priority: 10, Weight: 5, Port: 5222, Service: "_xmpp", Protocol: "_tcp"},
+			}}
+			server := newFakeZoneServer(t, zone)
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+
+			updated, err := provider.UpdateTTL(context.Background(), "example.com.", "SRV", tt.recName, time.Hour)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			changed := 0
+			for _, ttl := range tt.expectedTTL {
+				if ttl == 3600 {
+					changed++
+				}
+			}
+			if len(updated) != changed {
+				t.Errorf("Expected %d updated records, got %v", changed, updated)
+			}
+			if len(zone.records) != len(tt.expectedTTL) {
+				t.Fatalf("Expected %d records, got %v", len(tt.expectedTTL), zone.records)
+			}
+			for _, gr := range zone.records {
+				if ttl := tt.expectedTTL[gr.Service]; gr.TTL != ttl {
+					t.Errorf("TTL mismatch for %s: expected %d, got %d", gr.Service, ttl, gr.TTL)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateRecords(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+		{Type: "TXT", Name: "@", Data: "v=spf1 -all", TTL: 600},
+	}}
+	fake := newFakeZoneServer(t, zone)
+	var puts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			puts++
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	t.Run("Missing records", func(t *testing.T) {
+		_, err := provider.UpdateRecords(ctx, "example.com.", []libdns.Record{
+			libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.2")},
+			libdns.Address{Name: "api", IP: netip.MustParseAddr("192.0.2.3")},
+			libdns.TXT{Name: "www", Text: "hello"},
+		})
+		if !errors.Is(err, ErrRecordNotFound) {
+			t.Fatalf("Expected ErrRecordNotFound, got %v", err)
+		}
+		for _, name := range []string{"api.example.com (A)", "www.example.com (TXT)"} {
+			if !strings.Contains(err.Error(), name) {
+				t.Errorf("Expected the error to name %s, got %v", name, err)
+			}
+		}
+		if strings.Contains(err.Error(), "www.example.com (A)") {
+			t.Errorf("Expected the error not to name the existing record, got %v", err)
+		}
+		if puts != 0 {
+			t.Errorf("Expected no PUT request, got %d", puts)
+		}
+	})
+
+	t.Run("Existing records", func(t *testing.T) {
+		updated, err := provider.UpdateRecords(ctx, "example.com.", []libdns.Record{
+			libdns.Address{Name: "www.example.com.", IP: netip.MustParseAddr("192.0.2.2")},
+			libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.4")},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(updated) != 2 || updated[0].RR().TTL != 600*time.Second {
+			t.Errorf("Expected 2 records with the minimum TTL applied, got %v", updated)
+		}
+		if puts != 1 {
+			t.Errorf("Expected 1 PUT request, got %d", puts)
+		}
+
+		var data []string
+		for _, gr := range zone.records {
+			if gr.Type == "A" {
+				data = append(data, gr.Data)
+			}
+		}
+		if expected := []string{"192.0.2.2", "192.0.2.4"}; !slices.Equal(data, expected) {
+			t.Errorf("Records mismatch: expected %v, got %v", expected, data)
+		}
+	})
+}
+
+func TestUpdateRecordsSRVServices(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "SRV", Name: "@", Data: "xmpp.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5222, Service: "_xmpp", Protocol: "_tcp"},
+	}}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	// Another service at the same name does not make the record exist
+	sip := libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"}
+	if _, err := provider.UpdateRecords(ctx, "example.com.", []libdns.Record{sip}); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("Expected ErrRecordNotFound, got %v", err)
+	}
+
+	zone.records = append(zone.records, godaddyRecord{
+		Type: "SRV", Name: "@", Data: "old.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5060, Service: "_sip", Protocol: "_tcp",
+	})
+	if _, err := provider.UpdateRecords(ctx, "example.com.", []libdns.Record{sip}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The _sip._tcp record is updated while the _xmpp._tcp record is kept
+	expected := map[string]bool{
+		"_xmpp/xmpp.example.com": true,
+		"_sip/sip.example.com":   true,
+	}
+	if len(zone.records) != len(expected) {
+		t.Fatalf("Expected %d records, got %v", len(expected), zone.records)
+	}
+	for _, gr := range zone.records {
+		if key := gr.Service + "/" + gr.Data; !expected[key] {
+			t.Errorf("Unexpected record left in the zone: %s", key)
+		}
+	}
+}
+
+func TestMultipleRecordsPerName(t *testing.T) {
+	zone := &fakeZone{}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	www := []libdns.Record{
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.2")},
+	}
+	expected := []string{"192.0.2.1", "192.0.2.2"}
+	data := func(records []libdns.Record) []string {
+		var data []string
+		for _, record := range records {
+			data = append(data, record.RR().Data)
+		}
+		return data
+	}
+
+	set, err := provider.SetRecords(ctx, "example.com.", www)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := data(set); !slices.Equal(got, expected) {
+		t.Errorf("SetRecords mismatch: expected %v, got %v", expected, got)
+	}
+	if len(zone.records) != 2 {
+		t.Errorf("Expected 2 stored records, got %v", zone.records)
+	}
+
+	zone.records = nil
+	appended, err := provider.AppendRecords(ctx, "example.com.", www)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := data(appended); !slices.Equal(got, expected) {
+		t.Errorf("AppendRecords mismatch: expected %v, got %v", expected, got)
+	}
+
+	updated, err := provider.UpdateRecords(ctx, "example.com.", www)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := data(updated); !slices.Equal(got, expected) {
+		t.Errorf("UpdateRecords mismatch: expected %v, got %v", expected, got)
+	}
+}
+
+func TestZoneCache(t *testing.T) {
+	zone := &fakeZone{records: []godaddyRecord{
+		{Type: "A", Name: "www", Data: "192.0.2.1", TTL: 600},
+	}}
+	fake := newFakeZoneServer(t, zone)
+
+	listings := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/v1/domains/example.com/records" {
+			listings++
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := Provider{
+		APIToken:    "test:secret",
+		APIEndpoint: server.URL,
+		CacheTTL:    time.Minute,
+		now:         func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	getRecords := func(expectedListings, expectedRecords int) {
+		t.Helper()
+		records, err := provider.GetRecords(ctx, "example.com.")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(records) != expectedRecords {
+			t.Errorf("Expected %d records, got %d", expectedRecords, len(records))
+		}
+		if listings != expectedListings {
+			t.Errorf("Expected %d listings of the zone, got %d", expectedListings, listings)
+		}
+	}
+
+	getRecords(1, 1)
+
+	// Served from the cache
+	now = now.Add(30 * time.Second)
+	getRecords(1, 1)
+
+	// Writes drop the cached records
+	if _, err := provider.AppendRecords(ctx, "example.com.", []libdns.Record{
+		libdns.Address{Name: "api", IP: netip.MustParseAddr("192.0.2.2")},
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	getRecords(2, 2)
+
+	// Cached records expire after CacheTTL
+	now = now.Add(time.Minute)
+	getRecords(3, 2)
+}
+
+func TestConditionalGet(t *testing.T) {
+	tests := []struct {
+		name                string
+		enabled             bool
+		etag                string
+		expectedIfNoneMatch []string
+		expectedParsed      int
+	}{
+		{"Enabled", true, `"v1"`, []string{"", `"v1"`, `"v1"`}, 1},
+		{"Disabled", false, `"v1"`, []string{"", "", ""}, 3},
+		{"Without ETag", true, "", []string{"", "", ""}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ifNoneMatch []string
+			parsed := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+					if r.Header.Get("If-None-Match") == tt.etag {
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+				}
+				parsed++
+				w.Write([]byte(`[{"type":"A","name":"www","data":"192.0.2.1","ttl":600}]`))
+			}))
+			defer server.Close()
+
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, EnableConditionalGet: tt.enabled}
+			for range 3 {
+				records, err := provider.GetRecords(context.Background(), "example.com.")
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(records) != 1 || records[0].RR().Name != "www" {
+					t.Errorf("Expected the www record, got %v", records)
+				}
+			}
+
+			if !slices.Equal(ifNoneMatch, tt.expectedIfNoneMatch) {
+				t.Errorf("If-None-Match mismatch: expected %q, got %q", tt.expectedIfNoneMatch, ifNoneMatch)
+			}
+			if parsed != tt.expectedParsed {
+				t.Errorf("Expected %d full responses, got %d", tt.expectedParsed, parsed)
+			}
+		})
+	}
+}
+
+func TestDeleteRecordsByType(t *testing.T) {
+	newZone := func() *fakeZone {
+		return &fakeZone{records: []godaddyRecord{
+			{Type: "MX", Name: "@", Data: "10 mail1.example.com", TTL: 3600},
+			{Type: "MX", Name: "@", Data: "20 mail2.example.com", TTL: 3600},
+			{Type: "MX", Name: "sub", Data: "10 mail.example.com", TTL: 3600},
+			{Type: "TXT", Name: "sub", Data: "keep", TTL: 600},
+			{Type: "TXT", Name: "www", Data: "remove", TTL: 600},
+			{Type: "SRV", Name: "@", Data: "sip.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5060, Service: "_sip", Protocol: "_tcp"},
+			{Type: "SRV", Name: "@", Data: "xmpp.example.com", TTL: 600, Priority: 10, Weight: 5, Port: 5222, Service: "_xmpp", Protocol: "_tcp"},
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		delete    func(p *Provider) ([]libdns.Record, error)
+		deleted   int
+		remaining []string
+		requests  []string
+	}{
+		{
+			name: "By type",
+			delete: func(p *Provider) ([]libdns.Record, error) {
+				return p.DeleteRecordsByType(context.Background(), "example.com.", "mx")
+			},
+			deleted:   3,
+			remaining: []string{"TXT/sub", "TXT/www", "SRV/_sip._tcp", "SRV/_xmpp._tcp"},
+			requests: []string{
+				"GET /v1/domains/example.com/records/MX",
+				"DELETE /v1/domains/example.com/records/MX/@",
+				"DELETE /v1/domains/example.com/records/MX/sub",
+			},
+		},
+		{
+			name: "By type and name",
+			delete: func(p *Provider) ([]libdns.Record, error) {
+				return p.DeleteRecordsByTypeName(context.Background(), "example.com.", "TXT", "www.example.com.")
+			},
+			deleted:   1,
+			remaining: []string{"MX/@", "MX/@", "MX/sub", "TXT/sub", "SRV/_sip._tcp", "SRV/_xmpp._tcp"},
+			requests: []string{
+				"GET /v1/domains/example.com/records/TXT/www",
+				"DELETE /v1/domains/example.com/records/TXT/www",
+			},
+		},
+		{
+			name: "SRV by service and protocol",
+			delete: func(p *Provider) ([]libdns.Record, error) {
+				return p.DeleteRecordsByTypeName(context.Background(), "example.com.", "SRV", "_sip._tcp")
+			},
+			deleted:   1,
+			remaining: []string{"MX/@", "MX/@", "MX/sub", "TXT/sub", "TXT/www", "SRV/_xmpp._tcp"},
+			requests: []string{
+				"GET /v1/domains/example.com/records/SRV/@",
+				"PUT /v1/domains/example.com/records/SRV/@",
+			},
+		},
+		{
+			name: "SRV by name",
+			delete: func(p *Provider) ([]libdns.Record, error) {
+				return p.DeleteRecordsByTypeName(context.Background(), "example.com.", "SRV", "@")
+			},
+			deleted:   2,
+			remaining: []string{"MX/@", "MX/@", "MX/sub", "TXT/sub", "TXT/www"},
+			requests: []string{
+				"GET /v1/domains/example.com/records/SRV/@",
+				"DELETE /v1/domains/example.com/records/SRV/@",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := newZone()
+			fake := newFakeZoneServer(t, zone)
+
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				fake.Config.Handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+			deleted, err := tt.delete(&provider)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(deleted) != tt.deleted {
+				t.Errorf("Expected %d deleted records, got %v", tt.deleted, deleted)
+			}
+
+			var remaining []string
+			for _, gr := range zone.records {
+				remaining = append(remaining, gr.Type+"/"+convertToLibdnsRecord(gr).RR().Name)
+			}
+			if strings.Join(remaining, ",") != strings.Join(tt.remaining, ",") {
+				t.Errorf("Expected remaining records %v, got %v", tt.remaining, remaining)
+			}
+			if strings.Join(requests, ",") != strings.Join(tt.requests, ",") {
+				t.Errorf("Expected requests %v, got %v", tt.requests, requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package godaddy
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// supportedTypes lists the record types that can be written to GoDaddy
+var supportedTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CAA":   true,
+	"CNAME": true,
+	"DS":    true,
+	"HTTPS": true,
+	"MX":    true,
+	"NAPTR": true,
+	"NS":    true,
+	"SRV":   true,
+	"SSHFP": true,
+	"SVCB":  true,
+	"TLSA":  true,
+	"TXT":   true,
+}
+
+// validateRecord checks a converted record before it is sent, so that invalid
+// input fails with a descriptive error instead of an opaque HTTP 422
+func validateRecord(gr godaddyRecord) error {
+	recType := strings.ToUpper(gr.Type)
+	if !supportedTypes[recType] {
+		return fmt.Errorf("%s record %s: %w", gr.Type, gr.Name, ErrUnsupportedRecordType)
+	}
+
+	if err := validateName(gr.Name); err != nil {
+		return fmt.Errorf("%s record %s: %w", recType, gr.Name, err)
+	}
+
+	if recType == "CNAME" && gr.Name == "@" {
+		// GoDaddy has no ALIAS record to fall back on, see
+		// convertFromLibdnsRecord
+		return fmt.Errorf("CNAME record %s pointing to %s: %w; GoDaddy has no ALIAS records, "+
+			"so use A/AAAA records with the addresses of the target instead", gr.Name, gr.Data, ErrCNAMEAtApex)
+	}
+
+	return nil
+}
+
+// validateName checks that a relative record name is a valid DNS name
+func validateName(name string) error {
+	if name == "@" {
+		return nil
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("name is longer than 253 characters")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			return fmt.Errorf("name has an empty label")
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("label %q is longer than 63 characters", label)
+		}
+		if strings.IndexFunc(label, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("label %q contains whitespace", label)
+		}
+	}
+	return nil
+}
+
+// validateCNAMEConflicts checks that no name is given both a CNAME and
+// another record, as a CNAME must be the only record of its name
+func validateCNAMEConflicts(grs []godaddyRecord) error {
+	types := make(map[string]string)
+	for _, gr := range grs {
+		recType := strings.ToUpper(gr.Type)
+		name := strings.ToLower(gr.Name)
+		other, ok := types[name]
+		if !ok {
+			types[name] = recType
+			continue
+		}
+		if (recType == "CNAME") != (other == "CNAME") {
+			return fmt.Errorf("CNAME record %s: a CNAME cannot coexist with other records of the same name", gr.Name)
+		}
+	}
+	return nil
+}
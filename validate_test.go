@@ -0,0 +1,98 @@
+package godaddy
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func TestValidateRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  godaddyRecord
+		wantErr string
+	}{
+		{"Valid A record", godaddyRecord{Type: "A", Name: "www", Data: "192.0.2.1"}, ""},
+		{"Valid apex record", godaddyRecord{Type: "TXT", Name: "@", Data: "hello"}, ""},
+		{"Valid wildcard record", godaddyRecord{Type: "CNAME", Name: "*.sub", Data: "target.example.com"}, ""},
+		{"Lowercase type", godaddyRecord{Type: "txt", Name: "www", Data: "hello"}, ""},
+		{"Unsupported type", godaddyRecord{Type: "HINFO", Name: "www", Data: "cpu os"}, "HINFO record www"},
+		{"Empty label", godaddyRecord{Type: "A", Name: "a..b", Data: "192.0.2.1"}, "empty label"},
+		{"Label too long", godaddyRecord{Type: "A", Name: strings.Repeat("a", 64), Data: "192.0.2.1"}, "longer than 63"},
+		{"Whitespace in name", godaddyRecord{Type: "A", Name: "my host", Data: "192.0.2.1"}, "whitespace"},
+		{"CNAME at apex", godaddyRecord{Type: "CNAME", Name: "@", Data: "target.example.com"}, "zone apex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRecord(tt.record)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if err := validateRecord(godaddyRecord{Type: "HINFO", Name: "www"}); !errors.Is(err, ErrUnsupportedRecordType) {
+		t.Errorf("Expected ErrUnsupportedRecordType, got %v", err)
+	}
+}
+
+func TestValidateCNAMEConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []godaddyRecord
+		wantErr bool
+	}{
+		{"Single CNAME", []godaddyRecord{{Type: "CNAME", Name: "www"}}, false},
+		{"CNAME and A on different names", []godaddyRecord{{Type: "CNAME", Name: "www"}, {Type: "A", Name: "api"}}, false},
+		{"CNAME and A on the same name", []godaddyRecord{{Type: "A", Name: "www"}, {Type: "CNAME", Name: "www"}}, true},
+		{"Names compared ignoring case", []godaddyRecord{{Type: "CNAME", Name: "www"}, {Type: "TXT", Name: "WWW"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateCNAMEConflicts(tt.records); (err != nil) != tt.wantErr {
+				t.Errorf("validateCNAMEConflicts() error = %v; expected error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInvalidRecordsAreNotSent(t *testing.T) {
+	zone := &fakeZone{}
+	server := newFakeZoneServer(t, zone)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+	ctx := context.Background()
+
+	invalid := [][]libdns.Record{
+		{libdns.RR{Name: "www", Type: "HINFO", Data: "cpu os"}},
+		{libdns.CNAME{Name: "@", Target: "target.example.com."}},
+		{libdns.TXT{Name: "a..b", Text: "hello"}},
+		{
+			libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+			libdns.CNAME{Name: "www", Target: "target.example.com."},
+		},
+	}
+
+	for _, records := range invalid {
+		if _, err := provider.AppendRecords(ctx, "example.com.", records); err == nil {
+			t.Errorf("AppendRecords(%v): expected an error", records)
+		}
+		if _, err := provider.SetRecords(ctx, "example.com.", records); err == nil {
+			t.Errorf("SetRecords(%v): expected an error", records)
+		}
+	}
+	if len(zone.records) != 0 {
+		t.Errorf("Expected no records to be written, got %v", zone.records)
+	}
+}
@@ -0,0 +1,158 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// godaddyDomain represents a domain as returned by GoDaddy API
+type godaddyDomain struct {
+	Domain string `json:"domain"`
+	Status string `json:"status"`
+}
+
+// ListZones lists the active domains on the account as zones.
+func (p *Provider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	pageSize := p.getPageSize()
+	var zones []libdns.Zone
+	marker := ""
+
+	for {
+		query := url.Values{}
+		query.Set("statuses", "ACTIVE")
+		query.Set("limit", strconv.Itoa(pageSize))
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		url := fmt.Sprintf("%s/v1/domains?%s", p.getApiHost(), query.Encode())
+
+		resp, bodyBytes, err := p.doRequest(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isSuccess(resp.StatusCode) {
+			return nil, fmt.Errorf("API request failed: %w", newRequestError(resp, bodyBytes))
+		}
+
+		var resultObj []godaddyDomain
+		if err := json.Unmarshal(bodyBytes, &resultObj); err != nil {
+			return nil, fmt.Errorf("failed to parse response JSON: %w", err)
+		}
+
+		for _, domain := range resultObj {
+			if strings.EqualFold(domain.Status, "ACTIVE") {
+				zones = append(zones, libdns.Zone{Name: domain.Domain + "."})
+			}
+		}
+
+		// The domains endpoint pages with a marker, the last domain of the previous page
+		if len(resultObj) < pageSize {
+			return zones, nil
+		}
+		marker = resultObj[len(resultObj)-1].Domain
+	}
+}
+
+// Ping checks that the credentials are accepted by GoDaddy with a cheap
+// authenticated request listing at most one domain. An invalid key or secret
+// (HTTP 401) results in an error wrapping ErrUnauthorized, which also matches
+// errors.Is(err, &APIError{StatusCode: http.StatusUnauthorized}).
+func (p *Provider) Ping(ctx context.Context) error {
+	url := fmt.Sprintf("%s/v1/domains?limit=1", p.getApiHost())
+
+	resp, bodyBytes, err := p.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return fmt.Errorf("API request failed: %w", newRequestError(resp, bodyBytes))
+	}
+	return nil
+}
+
+// ZoneInfo holds the metadata of the registered domain hosting a zone
+type ZoneInfo struct {
+	// Domain is the registered domain, which differs from the zone for zones
+	// delegated below it
+	Domain string
+
+	// Status is the status of the domain, e.g. "ACTIVE"
+	Status string
+
+	// NameServers are the nameservers the domain is delegated to
+	NameServers []string
+
+	// Expires is when the domain registration expires
+	Expires time.Time
+}
+
+// UsesGoDaddyNameServers reports whether the domain is delegated to GoDaddy's
+// nameservers (*.domaincontrol.com), i.e. whether records written through the
+// API are served
+func (z ZoneInfo) UsesGoDaddyNameServers() bool {
+	if len(z.NameServers) == 0 {
+		return false
+	}
+	for _, ns := range z.NameServers {
+		ns = strings.ToLower(strings.TrimSuffix(ns, "."))
+		if !strings.HasSuffix(ns, ".domaincontrol.com") {
+			return false
+		}
+	}
+	return true
+}
+
+// godaddyDomainDetail represents the details of a domain as returned by
+// GET /v1/domains/{domain}
+type godaddyDomainDetail struct {
+	Domain      string    `json:"domain"`
+	Status      string    `json:"status"`
+	NameServers []string  `json:"nameServers"`
+	Expires     time.Time `json:"expires"`
+}
+
+// GetZoneInfo returns the metadata of the registered domain hosting the zone.
+// Results are cached for an hour, as they rarely change. If the domain is not
+// on the account, the error wraps ErrZoneNotFound.
+func (p *Provider) GetZoneInfo(ctx context.Context, zone string) (ZoneInfo, error) {
+	domain := getDomain(zone)
+	state := p.getState()
+	if info, ok := state.cachedZoneInfo(domain, p.getNow()); ok {
+		return info, nil
+	}
+
+	url := fmt.Sprintf("%s/v1/domains/%s", p.getApiHost(), domain)
+
+	resp, bodyBytes, err := p.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ZoneInfo{}, err
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return ZoneInfo{}, fmt.Errorf("API request failed: %w", newZoneError(resp, bodyBytes, zone))
+	}
+
+	var detail godaddyDomainDetail
+	if err := json.Unmarshal(bodyBytes, &detail); err != nil {
+		return ZoneInfo{}, fmt.Errorf("failed to parse response JSON: %w", err)
+	}
+
+	info := ZoneInfo{
+		Domain:      domain,
+		Status:      detail.Status,
+		NameServers: detail.NameServers,
+		Expires:     detail.Expires,
+	}
+	state.cacheZoneInfo(domain, info, p.getNow().Add(zoneInfoCacheTTL))
+	return info, nil
+}
@@ -0,0 +1,186 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListZones(t *testing.T) {
+	domains := []godaddyDomain{
+		{Domain: "a.com", Status: "ACTIVE"},
+		{Domain: "b.com", Status: "CANCELLED"},
+		{Domain: "c.com", Status: "ACTIVE"},
+		{Domain: "d.com", Status: "ACTIVE"},
+	}
+
+	var markers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/domains" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if statuses := r.URL.Query().Get("statuses"); statuses != "ACTIVE" {
+			t.Errorf("Expected statuses=ACTIVE, got %s", statuses)
+		}
+
+		marker := r.URL.Query().Get("marker")
+		markers = append(markers, marker)
+
+		start := 0
+		for i, domain := range domains {
+			if domain.Domain == marker {
+				start = i + 1
+			}
+		}
+		end := min(start+2, len(domains))
+		json.NewEncoder(w).Encode(domains[start:end])
+	}))
+	defer server.Close()
+
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, PageSize: 2}
+	zones, err := provider.ListZones(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"a.com.", "c.com.", "d.com."}
+	if len(zones) != len(expected) {
+		t.Fatalf("Expected %d zones, got %d: %v", len(expected), len(zones), zones)
+	}
+	for i, name := range expected {
+		if zones[i].Name != name {
+			t.Errorf("Zone %d: expected %s, got %s", i, name, zones[i].Name)
+		}
+	}
+
+	expectedMarkers := []string{"", "b.com", "d.com"}
+	if len(markers) != len(expectedMarkers) {
+		t.Fatalf("Expected %d requests, got %d: %v", len(expectedMarkers), len(markers), markers)
+	}
+	for i, marker := range expectedMarkers {
+		if markers[i] != marker {
+			t.Errorf("Request %d: expected marker %q, got %q", i, marker, markers[i])
+		}
+	}
+}
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		expectErr    bool
+		unauthorized bool
+	}{
+		{"Valid credentials", http.StatusOK, `[]`, false, false},
+		{"Invalid credentials", http.StatusUnauthorized, `{"code":"UNABLE_TO_AUTHENTICATE","message":"Unable to authenticate"}`, true, true},
+		{"Server error", http.StatusInternalServerError, `{"code":"INTERNAL_SERVER_ERROR","message":"Internal error"}`, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/domains" || r.URL.Query().Get("limit") != "1" {
+					t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL}
+			err := provider.Ping(context.Background())
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Ping() error = %v; expected error: %v", err, tt.expectErr)
+			}
+			if unauthorized := errors.Is(err, &APIError{StatusCode: http.StatusUnauthorized}); unauthorized != tt.unauthorized {
+				t.Errorf("Unauthorized mismatch: expected %v, got %v (%v)", tt.unauthorized, unauthorized, err)
+			}
+		})
+	}
+}
+
+func TestGetZoneInfo(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/v1/domains/example.com":
+			w.Write([]byte(`{
+				"domain": "example.com",
+				"domainId": 1234,
+				"status": "ACTIVE",
+				"nameServers": ["ns01.domaincontrol.com", "ns02.domaincontrol.com"],
+				"expires": "2027-03-01T12:00:00.000Z"
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"NOT_FOUND","message":"Domain not found for shopper"}`))
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	provider := Provider{APIToken: "test:secret", APIEndpoint: server.URL, now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	info, err := provider.GetZoneInfo(ctx, "k8s.example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Domain != "example.com" || info.Status != "ACTIVE" {
+		t.Errorf("Unexpected zone info: %+v", info)
+	}
+	if expected := time.Date(2027, 3, 1, 12, 0, 0, 0, time.UTC); !info.Expires.Equal(expected) {
+		t.Errorf("Expires mismatch: expected %s, got %s", expected, info.Expires)
+	}
+	if len(info.NameServers) != 2 || !info.UsesGoDaddyNameServers() {
+		t.Errorf("Expected the GoDaddy nameservers, got %v", info.NameServers)
+	}
+
+	// Served from the cache, which callers cannot modify
+	info.NameServers[0] = "ns1.example.net"
+	info, err = provider.GetZoneInfo(ctx, "example.com.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 1 || info.NameServers[0] != "ns01.domaincontrol.com" {
+		t.Errorf("Expected the cached zone info after %d calls, got %+v", calls, info)
+	}
+
+	// Fetched again once expired
+	now = now.Add(2 * time.Hour)
+	if _, err := provider.GetZoneInfo(ctx, "example.com."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+
+	if _, err := provider.GetZoneInfo(ctx, "missing.com."); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected ErrZoneNotFound, got %v", err)
+	}
+}
+
+func TestUsesGoDaddyNameServers(t *testing.T) {
+	tests := []struct {
+		nameServers []string
+		expected    bool
+	}{
+		{[]string{"ns01.domaincontrol.com", "NS02.DomainControl.com."}, true},
+		{[]string{"ns01.domaincontrol.com", "ns1.example.net"}, false},
+		{[]string{"ns1.example.net"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		info := ZoneInfo{NameServers: tt.nameServers}
+		if got := info.UsesGoDaddyNameServers(); got != tt.expected {
+			t.Errorf("UsesGoDaddyNameServers(%v) mismatch: expected %v, got %v", tt.nameServers, tt.expected, got)
+		}
+	}
+}